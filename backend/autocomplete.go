@@ -0,0 +1,157 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Nombre de suggestions renvoyées par défaut et au maximum
+const (
+	defaultCompletions = 10
+	maxCompletions     = 50
+)
+
+// cardCompletions est l'index d'autocomplétion courant
+var cardCompletions atomic.Pointer[autocompleteIndex]
+
+// autocompleteIndex est un index trié de clés normalisées. Chaque nom est
+// indexé en entier et à partir de chacun de ses mots, ce qui permet de
+// compléter "blos" en "Ash Blossom & Joyous Spring".
+type autocompleteIndex struct {
+	entries []completionEntry
+}
+
+type completionEntry struct {
+	key        string
+	suggestion Suggestion
+	wordStart  bool // la clé commence au milieu du nom
+}
+
+// Suggestion est une proposition d'autocomplétion
+type Suggestion struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"` // card ou archetype
+	ID   int    `json:"id,omitempty"`
+}
+
+// buildAutocompleteIndex indexe les noms de cartes et les archétypes
+func buildAutocompleteIndex(cards []Card, archetypes []string) *autocompleteIndex {
+	idx := &autocompleteIndex{}
+	add := func(name string, s Suggestion) {
+		words := strings.Fields(normalizeName(name))
+		for i := range words {
+			idx.entries = append(idx.entries, completionEntry{
+				key:        strings.Join(words[i:], " "),
+				suggestion: s,
+				wordStart:  i > 0,
+			})
+		}
+	}
+	for _, c := range cards {
+		add(c.Name, Suggestion{Name: c.Name, Kind: "card", ID: c.ID})
+	}
+	for _, a := range archetypes {
+		add(a, Suggestion{Name: a, Kind: "archetype"})
+	}
+	sort.Slice(idx.entries, func(i, j int) bool { return idx.entries[i].key < idx.entries[j].key })
+	return idx
+}
+
+// complete retourne au plus limit suggestions commençant par prefix. Les noms
+// qui commencent par le préfixe passent avant ceux dont un mot le contient,
+// puis les plus courts avant les plus longs.
+func (idx *autocompleteIndex) complete(prefix, kind string, limit int) []Suggestion {
+	prefix = normalizeName(prefix)
+	if prefix == "" {
+		return nil
+	}
+
+	better := func(a, b completionEntry) bool {
+		if a.wordStart != b.wordStart {
+			return !a.wordStart
+		}
+		if len(a.suggestion.Name) != len(b.suggestion.Name) {
+			return len(a.suggestion.Name) < len(b.suggestion.Name)
+		}
+		return a.suggestion.Name < b.suggestion.Name
+	}
+
+	// Sélection des meilleures entrées sans trier toutes les correspondances
+	var best []completionEntry
+	start := sort.Search(len(idx.entries), func(i int) bool { return idx.entries[i].key >= prefix })
+	for _, e := range idx.entries[start:] {
+		if !strings.HasPrefix(e.key, prefix) {
+			break
+		}
+		if kind != "" && e.suggestion.Kind != kind {
+			continue
+		}
+		if i := indexOfSuggestion(best, e.suggestion); i >= 0 {
+			if !better(e, best[i]) {
+				continue
+			}
+			best = append(best[:i], best[i+1:]...)
+		}
+		if len(best) == limit && !better(e, best[limit-1]) {
+			continue
+		}
+		pos := sort.Search(len(best), func(i int) bool { return better(e, best[i]) })
+		if len(best) < limit {
+			best = append(best, completionEntry{})
+		}
+		copy(best[pos+1:], best[pos:])
+		best[pos] = e
+	}
+
+	suggestions := make([]Suggestion, len(best))
+	for i, e := range best {
+		suggestions[i] = e.suggestion
+	}
+	return suggestions
+}
+
+func indexOfSuggestion(entries []completionEntry, s Suggestion) int {
+	for i, e := range entries {
+		if e.suggestion == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// getAutocomplete propose des noms de cartes et d'archétypes à chaque frappe
+func getAutocomplete(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	prefix := r.URL.Query().Get("q")
+	if strings.TrimSpace(prefix) == "" {
+		writeJSON(w, http.StatusBadRequest, APIResponse{Error: "Paramètre 'q' requis", Status: "error"})
+		return
+	}
+	kind := r.URL.Query().Get("kind")
+	if kind != "" && kind != "card" && kind != "archetype" {
+		writeJSON(w, http.StatusBadRequest, APIResponse{Error: "Paramètre 'kind' invalide (card ou archetype)", Status: "error"})
+		return
+	}
+	limit := defaultCompletions
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeJSON(w, http.StatusBadRequest, APIResponse{Error: "Paramètre 'limit' invalide: " + v, Status: "error"})
+			return
+		}
+		limit = min(n, maxCompletions)
+	}
+
+	idx := cardCompletions.Load()
+	if idx == nil {
+		writeJSON(w, http.StatusServiceUnavailable, APIResponse{Error: "Autocomplétion indisponible: le miroir n'est pas encore synchronisé", Status: "error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, APIResponse{Data: idx.complete(prefix, kind, limit), Status: "success"})
+}
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// banlistReloadInterval est la fréquence de vérification des fichiers de banlist
+const banlistReloadInterval = 10 * time.Second
+
+// banlistFormats associe les variantes acceptées au nom canonique du format
+var banlistFormats = map[string]string{
+	"tcg":         "TCG",
+	"ocg":         "OCG",
+	"md":          "Master Duel",
+	"master duel": "Master Duel",
+	"masterduel":  "Master Duel",
+	"goat":        "GOAT",
+	"edison":      "Edison",
+}
+
+// normalizeFormat retourne le nom canonique d'un format de banlist
+func normalizeFormat(format string) (string, bool) {
+	canonical, ok := banlistFormats[strings.ToLower(strings.TrimSpace(format))]
+	return canonical, ok
+}
+
+// banlists est le magasin de banlists utilisé par les handlers
+var banlists *banlistStore
+
+// banlistStore charge les banlists depuis des dossiers de fichiers JSON (un
+// fichier par liste, l'identifiant étant le nom du fichier) et les recharge à
+// chaud quand les fichiers changent. Les listes livrées avec le code sont dans
+// dir ; celles importées à l'exécution sont écrites dans importDir.
+type banlistStore struct {
+	dir       string
+	importDir string
+
+	mu        sync.RWMutex
+	lists     []Banlist // triées par date décroissante
+	signature string
+}
+
+func newBanlistStore(dir, importDir string) *banlistStore {
+	return &banlistStore{dir: dir, importDir: importDir}
+}
+
+// all retourne les banlists, de la plus récente à la plus ancienne
+func (s *banlistStore) all() []Banlist {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lists
+}
+
+// find retourne la banlist portant l'identifiant donné
+func (s *banlistStore) find(id string) (Banlist, bool) {
+	for _, b := range s.all() {
+		if b.ID == id {
+			return b, true
+		}
+	}
+	return Banlist{}, false
+}
+
+// byFormat retourne les banlists du format donné, de la plus récente à la plus
+// ancienne
+func (s *banlistStore) byFormat(format string) []Banlist {
+	lists := []Banlist{}
+	for _, b := range s.all() {
+		if b.Format == format {
+			lists = append(lists, b)
+		}
+	}
+	return lists
+}
+
+// effective retourne la banlist du format en vigueur à la date donnée, c'est à
+// dire la plus récente publiée au plus tard ce jour-là
+func (s *banlistStore) effective(format, date string) (Banlist, bool) {
+	for _, b := range s.all() {
+		if b.Format == format && b.BanlistDate <= date {
+			return b, true
+		}
+	}
+	return Banlist{}, false
+}
+
+// load relit tous les fichiers du dossier. Si l'un d'eux est invalide, les
+// listes déjà chargées sont conservées.
+func (s *banlistStore) load() error {
+	files, err := s.files()
+	if err != nil {
+		return err
+	}
+
+	lists := make([]Banlist, 0, len(files))
+	seen := make(map[string]string)
+	for _, file := range files {
+		var b Banlist
+		if err := readJSONFile(file, &b); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		b.ID = strings.TrimSuffix(filepath.Base(file), ".json")
+		if err := b.validate(); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		if other, ok := seen[b.ID]; ok {
+			return fmt.Errorf("%s: identifiant %s déjà utilisé par %s", file, b.ID, other)
+		}
+		seen[b.ID] = file
+		lists = append(lists, b)
+	}
+	sortBanlists(lists)
+
+	signature, err := s.dirSignature()
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.lists = lists
+	s.signature = signature
+	s.mu.Unlock()
+	return nil
+}
+
+// save écrit la banlist dans le dossier d'import sous son identifiant et
+// l'ajoute aux listes chargées. Les autres fichiers ne sont pas relus : la
+// signature n'est mise à jour que si elle correspondait déjà au dossier, sinon
+// watch recharge tout au tick suivant.
+func (s *banlistStore) save(b Banlist) error {
+	if err := b.validate(); err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(s.dir, b.ID+".json")); err == nil {
+		return fmt.Errorf("identifiant %s déjà utilisé par une banlist livrée", b.ID)
+	}
+	if err := os.MkdirAll(s.importDir, 0o755); err != nil {
+		return err
+	}
+	b.CardsVerified, b.CardIssues = false, nil
+	stored := b
+	stored.ID = ""
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	before, err := s.dirSignature()
+	upToDate := err == nil && before == s.signature
+	if err := writeJSONFile(filepath.Join(s.importDir, b.ID+".json"), stored); err != nil {
+		return err
+	}
+	if upToDate {
+		if signature, err := s.dirSignature(); err == nil {
+			s.signature = signature
+		}
+	}
+
+	lists := make([]Banlist, 0, len(s.lists)+1)
+	for _, other := range s.lists {
+		if other.ID != b.ID {
+			lists = append(lists, other)
+		}
+	}
+	lists = append(lists, b)
+	sortBanlists(lists)
+	s.lists = lists
+	return nil
+}
+
+// unverified indique si une liste n'a pas encore été vérifiée par checkCards
+func (s *banlistStore) unverified() bool {
+	for _, b := range s.all() {
+		if !b.CardsVerified {
+			return true
+		}
+	}
+	return false
+}
+
+// watch vérifie les identifiants de cartes, puis recharge les banlists dès
+// qu'un fichier est ajouté, modifié ou supprimé. Une vérification interrompue
+// (réseau indisponible, miroir pas encore synchronisé) est relancée au tick
+// suivant, de même que celle d'une liste importée entre-temps.
+func (s *banlistStore) watch(ctx context.Context, client CardClient) {
+	ticker := time.NewTicker(banlistReloadInterval)
+	defer ticker.Stop()
+
+	s.checkCards(ctx, client)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		signature, err := s.dirSignature()
+		s.mu.RLock()
+		changed := err == nil && signature != s.signature
+		s.mu.RUnlock()
+		if changed {
+			if err := s.load(); err != nil {
+				log.Printf("⚠️ Rechargement des banlists impossible, anciennes listes conservées: %v", err)
+				// Ne pas rejouer l'erreur tant que les fichiers ne changent pas
+				s.mu.Lock()
+				s.signature = signature
+				s.mu.Unlock()
+			} else {
+				log.Printf("🔁 Banlists rechargées: %d listes", len(s.all()))
+			}
+		}
+		if s.unverified() {
+			s.checkCards(ctx, client)
+		}
+	}
+}
+
+// dirSignature résume les noms, tailles et dates de modification des fichiers
+func (s *banlistStore) dirSignature() (string, error) {
+	files, err := s.files()
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "%s:%d:%d;", file, info.Size(), info.ModTime().UnixNano())
+	}
+	return b.String(), nil
+}
+
+// files liste les fichiers de banlist des deux dossiers. Le dossier d'import
+// peut ne pas encore exister.
+func (s *banlistStore) files() ([]string, error) {
+	var files []string
+	for _, dir := range []string{s.dir, s.importDir} {
+		found, err := filepath.Glob(filepath.Join(dir, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(found)
+		files = append(files, found...)
+	}
+	return files, nil
+}
+
+// checkCards vérifie que chaque CardID correspond à une vraie carte portant
+// le nom indiqué. Les écarts sont journalisés et joints aux listes
+// (card_issues) pour être renvoyés par /api/banlist. Elle s'arrête avec une
+// erreur, sans rien modifier, si une carte ne peut pas être consultée.
+func (s *banlistStore) checkCards(ctx context.Context, client CardClient) error {
+	issues := make(map[string][]CardIssue)
+	problems := 0
+	for _, b := range s.all() {
+		for _, bc := range b.BanCards {
+			card, err := client.CardByID(ctx, bc.CardID)
+			issue := CardIssue{CardID: bc.CardID, CardName: bc.CardName}
+			switch {
+			case errors.Is(err, errCardNotFound):
+				log.Printf("⚠️ Banlist %s: carte %d (%s) introuvable", b.ID, bc.CardID, bc.CardName)
+				issue.Problem = "not_found"
+			case err != nil:
+				log.Printf("⚠️ Banlist %s: vérification de la carte %d impossible, nouvel essai dans %s: %v", b.ID, bc.CardID, banlistReloadInterval, err)
+				return err
+			case !strings.EqualFold(card.Name, bc.CardName):
+				log.Printf("⚠️ Banlist %s: la carte %d s'appelle %q et non %q", b.ID, bc.CardID, card.Name, bc.CardName)
+				issue.Problem, issue.ActualName = "name_mismatch", card.Name
+			default:
+				continue
+			}
+			issues[b.ID] = append(issues[b.ID], issue)
+			problems++
+		}
+	}
+
+	s.mu.Lock()
+	lists := make([]Banlist, len(s.lists))
+	for i, b := range s.lists {
+		b.CardsVerified, b.CardIssues = true, issues[b.ID]
+		lists[i] = b
+	}
+	s.lists = lists
+	s.mu.Unlock()
+
+	if problems == 0 {
+		log.Printf("✅ Banlists vérifiées: toutes les cartes existent")
+	}
+	return nil
+}
+
+// validate vérifie la structure d'une banlist chargée depuis un fichier
+func (b Banlist) validate() error {
+	if b.BanlistName == "" {
+		return errors.New("banlist_name manquant")
+	}
+	if canonical, ok := normalizeFormat(b.Format); !ok || canonical != b.Format {
+		return fmt.Errorf("format invalide: %q", b.Format)
+	}
+	if _, err := time.Parse("2006-01-02", b.BanlistDate); err != nil {
+		return fmt.Errorf("banlist_date invalide: %q", b.BanlistDate)
+	}
+	seen := make(map[int]bool)
+	for i, c := range b.BanCards {
+		if c.CardName == "" || c.CardID <= 0 {
+			return fmt.Errorf("carte n°%d: card_name et card_id sont requis", i+1)
+		}
+		if c.BanStatus == "" {
+			return fmt.Errorf("carte %s: ban_status manquant", c.CardName)
+		}
+		if seen[c.CardID] {
+			return fmt.Errorf("carte %s présente deux fois", c.CardName)
+		}
+		seen[c.CardID] = true
+	}
+	return nil
+}
+
+// sortBanlists trie de la plus récente à la plus ancienne, puis par nom
+func sortBanlists(lists []Banlist) {
+	sort.SliceStable(lists, func(i, j int) bool {
+		if lists[i].BanlistDate != lists[j].BanlistDate {
+			return lists[i].BanlistDate > lists[j].BanlistDate
+		}
+		return lists[i].BanlistName < lists[j].BanlistName
+	})
+}
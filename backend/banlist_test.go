@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCheckCards(t *testing.T) {
+	store := newBanlistStore(t.TempDir(), t.TempDir())
+	store.lists = []Banlist{{
+		ID: "tcg-test",
+		BanCards: []BannedCard{
+			{CardName: "Ash Blossom & Joyous Spring", CardID: 14558127},
+			{CardName: "Kashtira Argodem", CardID: 100369999},
+			{CardName: "Pot of Greed", CardID: 23434538},
+		},
+	}}
+
+	// Réseau indisponible : rien n'est marqué comme vérifié
+	if err := store.checkCards(context.Background(), &fakeCardClient{err: errors.New("hors ligne")}); err == nil {
+		t.Fatal("checkCards sans erreur malgré l'API indisponible")
+	}
+	if store.all()[0].CardsVerified {
+		t.Error("banlist marquée vérifiée après un échec")
+	}
+
+	client := &fakeCardClient{cards: []Card{
+		{ID: 14558127, Name: "Ash Blossom & Joyous Spring"},
+		{ID: 23434538, Name: "Maxx \"C\""},
+	}}
+	if err := store.checkCards(context.Background(), client); err != nil {
+		t.Fatal(err)
+	}
+	got := store.all()[0]
+	want := []CardIssue{
+		{CardID: 100369999, CardName: "Kashtira Argodem", Problem: "not_found"},
+		{CardID: 23434538, CardName: "Pot of Greed", Problem: "name_mismatch", ActualName: "Maxx \"C\""},
+	}
+	if !got.CardsVerified || !reflect.DeepEqual(got.CardIssues, want) {
+		t.Errorf("CardsVerified = %v, CardIssues = %+v, want %+v", got.CardsVerified, got.CardIssues, want)
+	}
+}
+
+func TestSaveRefreshesSignature(t *testing.T) {
+	store := newBanlistStore(t.TempDir(), t.TempDir())
+	if err := store.load(); err != nil {
+		t.Fatal(err)
+	}
+	list := Banlist{ID: "tcg-2026-10-17-ygoprodeck", BanlistName: "TCG", Format: "TCG", BanlistDate: "2026-10-17", BanCards: []BannedCard{}}
+	if err := store.save(list); err != nil {
+		t.Fatal(err)
+	}
+	signature, err := store.dirSignature()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if store.signature != signature {
+		t.Error("signature non mise à jour : watch relirait tous les fichiers")
+	}
+	if !store.unverified() {
+		t.Error("la liste importée doit être vérifiée au tick suivant")
+	}
+
+	// Un fichier ajouté par ailleurs doit encore être vu par watch
+	other := list
+	other.ID = ""
+	if err := writeJSONFile(filepath.Join(store.importDir, "ocg-2026-10-17-ygoprodeck.json"), other); err != nil {
+		t.Fatal(err)
+	}
+	list.ID = "goat-2026-10-17-ygoprodeck"
+	if err := store.save(list); err != nil {
+		t.Fatal(err)
+	}
+	if signature, _ := store.dirSignature(); store.signature == signature {
+		t.Error("signature mise à jour malgré un fichier non relu")
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// BanlistDiff décrit les changements entre deux banlists
+type BanlistDiff struct {
+	From        BanlistRef          `json:"from"`
+	To          BanlistRef          `json:"to"`
+	Hits        int                 `json:"hits"`        // cartes plus restreintes
+	Relaxed     int                 `json:"relaxed"`     // cartes moins restreintes
+	Transitions []BanlistTransition `json:"transitions"` // changements groupés
+}
+
+// BanlistRef identifie une banlist dans un diff
+type BanlistRef struct {
+	ID     string `json:"id"`
+	Name   string `json:"banlist_name"`
+	Format string `json:"format"`
+	Date   string `json:"banlist_date"`
+}
+
+// BanlistTransition regroupe les cartes passées d'un statut à un autre
+type BanlistTransition struct {
+	Transition string       `json:"transition"` // ex. "Limited → Forbidden"
+	From       BanStatus    `json:"from"`
+	To         BanStatus    `json:"to"`
+	Cards      []BannedCard `json:"cards"`
+}
+
+func refOf(b Banlist) BanlistRef {
+	return BanlistRef{ID: b.ID, Name: b.BanlistName, Format: b.Format, Date: b.BanlistDate}
+}
+
+// diffBanlists compare deux listes. Une carte absente d'une liste est
+// considérée comme illimitée ; les entrées de la liste to sont renvoyées.
+// Les transitions sont libellées dans la langue donnée.
+func diffBanlists(from, to Banlist, lang string) BanlistDiff {
+	before := make(map[int]BannedCard, len(from.BanCards))
+	for _, c := range from.BanCards {
+		before[c.CardID] = c
+	}
+	after := make(map[int]BannedCard, len(to.BanCards))
+	for _, c := range to.BanCards {
+		after[c.CardID] = c
+	}
+
+	diff := BanlistDiff{From: refOf(from), To: refOf(to), Transitions: []BanlistTransition{}}
+	groups := make(map[[2]BanStatus]*BanlistTransition)
+	record := func(card BannedCard, oldStatus, newStatus BanStatus) {
+		if oldStatus.Copies() == newStatus.Copies() {
+			return
+		}
+		if newStatus.Copies() < oldStatus.Copies() {
+			diff.Hits++
+		} else {
+			diff.Relaxed++
+		}
+		key := [2]BanStatus{oldStatus, newStatus}
+		g, ok := groups[key]
+		if !ok {
+			g = &BanlistTransition{
+				Transition: oldStatus.Label(lang) + " → " + newStatus.Label(lang),
+				From:       oldStatus,
+				To:         newStatus,
+			}
+			groups[key] = g
+		}
+		g.Cards = append(g.Cards, card)
+	}
+
+	for _, c := range to.BanCards {
+		oldStatus := Unlimited
+		if prev, ok := before[c.CardID]; ok {
+			oldStatus = prev.BanStatus
+		}
+		record(c, oldStatus, c.BanStatus)
+	}
+	for _, c := range from.BanCards {
+		if _, ok := after[c.CardID]; !ok {
+			record(c, c.BanStatus, Unlimited)
+		}
+	}
+
+	// Les durcissements d'abord, du plus sévère au plus léger
+	keys := make([][2]BanStatus, 0, len(groups))
+	for k := range groups {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		oi, ni := keys[i][0].Copies(), keys[i][1].Copies()
+		oj, nj := keys[j][0].Copies(), keys[j][1].Copies()
+		if hi, hj := ni < oi, nj < oj; hi != hj {
+			return hi
+		}
+		if ni != nj {
+			return ni < nj
+		}
+		return oi < oj
+	})
+	for _, k := range keys {
+		g := groups[k]
+		sort.Slice(g.Cards, func(i, j int) bool { return g.Cards[i].CardName < g.Cards[j].CardName })
+		diff.Transitions = append(diff.Transitions, *g)
+	}
+	return diff
+}
+
+// getBanlistDiff compare deux banlists désignées par leurs identifiants
+// (from, to) ou par un format et deux dates (from_date, to_date). Avec un
+// format seul, les deux listes les plus récentes sont comparées.
+func getBanlistDiff(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	lang, err := parseLang(r.URL.Query().Get("lang"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, APIResponse{Error: err.Error(), Status: "error"})
+		return
+	}
+	from, to, status, err := resolveBanlistPair(r)
+	if err != nil {
+		writeJSON(w, status, APIResponse{Error: err.Error(), Status: "error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, APIResponse{Data: diffBanlists(from, to, lang), Status: "success"})
+}
+
+// resolveBanlistPair retrouve les deux listes à comparer à partir de la
+// requête et renvoie le code HTTP adapté en cas d'erreur
+func resolveBanlistPair(r *http.Request) (Banlist, Banlist, int, error) {
+	query := r.URL.Query()
+	if fromID, toID := query.Get("from"), query.Get("to"); fromID != "" || toID != "" {
+		if fromID == "" || toID == "" {
+			return Banlist{}, Banlist{}, http.StatusBadRequest, errors.New("Paramètres 'from' et 'to' requis ensemble")
+		}
+		from, ok := banlists.find(fromID)
+		if !ok {
+			return Banlist{}, Banlist{}, http.StatusNotFound, fmt.Errorf("Banlist inconnue: %s", fromID)
+		}
+		to, ok := banlists.find(toID)
+		if !ok {
+			return Banlist{}, Banlist{}, http.StatusNotFound, fmt.Errorf("Banlist inconnue: %s", toID)
+		}
+		return from, to, http.StatusOK, nil
+	}
+
+	format, ok := normalizeFormat(query.Get("format"))
+	if !ok {
+		return Banlist{}, Banlist{}, http.StatusBadRequest, errors.New("Paramètres 'from' et 'to', ou 'format' valide, requis")
+	}
+	fromDate, toDate := query.Get("from_date"), query.Get("to_date")
+	if fromDate == "" {
+		lists := banlists.byFormat(format)
+		if len(lists) < 2 {
+			return Banlist{}, Banlist{}, http.StatusNotFound, fmt.Errorf("Moins de deux banlists %s disponibles", format)
+		}
+		return lists[1], lists[0], http.StatusOK, nil
+	}
+	if toDate == "" {
+		toDate = time.Now().Format("2006-01-02")
+	}
+	for _, d := range []string{fromDate, toDate} {
+		if _, err := time.Parse("2006-01-02", d); err != nil {
+			return Banlist{}, Banlist{}, http.StatusBadRequest, fmt.Errorf("Date invalide (AAAA-MM-JJ): %s", d)
+		}
+	}
+	from, ok := banlists.effective(format, fromDate)
+	if !ok {
+		return Banlist{}, Banlist{}, http.StatusNotFound, fmt.Errorf("Aucune banlist %s en vigueur le %s", format, fromDate)
+	}
+	to, ok := banlists.effective(format, toDate)
+	if !ok {
+		return Banlist{}, Banlist{}, http.StatusNotFound, fmt.Errorf("Aucune banlist %s en vigueur le %s", format, toDate)
+	}
+	return from, to, http.StatusOK, nil
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CardBanHistory retrace le statut d'une carte dans chaque format ayant au
+// moins une banlist, indexé par nom canonique du format (TCG, OCG, GOAT...)
+type CardBanHistory struct {
+	CardID   int                       `json:"card_id"`
+	CardName string                    `json:"card_name"`
+	Formats  map[string]FormatTimeline `json:"formats"`
+}
+
+// FormatTimeline est l'historique d'une carte dans un format
+type FormatTimeline struct {
+	Format  string         `json:"format"`
+	Current BanStatus      `json:"current"`
+	Changes []StatusChange `json:"changes"` // du plus ancien au plus récent
+}
+
+// StatusChange est un changement de statut entré en vigueur avec une banlist
+type StatusChange struct {
+	Date        string    `json:"date"`
+	BanlistID   string    `json:"banlist_id"`
+	BanlistName string    `json:"banlist_name"`
+	Transition  string    `json:"transition"` // ex. "Forbidden → Limited"
+	From        BanStatus `json:"from"`
+	To          BanStatus `json:"to"`
+}
+
+// timeline parcourt les banlists du format de la plus ancienne à la plus
+// récente. Une carte absente d'une liste est illimitée, comme pour les diffs.
+func (s *banlistStore) timeline(format string, matches func(BannedCard) bool, lang string) FormatTimeline {
+	t := FormatTimeline{Format: format, Current: Unlimited, Changes: []StatusChange{}}
+	lists := s.byFormat(format)
+	for i := len(lists) - 1; i >= 0; i-- {
+		b := lists[i]
+		status := Unlimited
+		for _, c := range b.BanCards {
+			if matches(c) {
+				status = c.BanStatus
+				break
+			}
+		}
+		if status == t.Current {
+			continue
+		}
+		t.Changes = append(t.Changes, StatusChange{
+			Date:        b.BanlistDate,
+			BanlistID:   b.ID,
+			BanlistName: b.BanlistName,
+			Transition:  t.Current.Label(lang) + " → " + status.Label(lang),
+			From:        t.Current,
+			To:          status,
+		})
+		t.Current = status
+	}
+	return t
+}
+
+// findBannedCard cherche une carte dans les banlists par identifiant ou par nom
+func (s *banlistStore) findBannedCard(id int, name string) (BannedCard, bool) {
+	for _, b := range s.all() {
+		for _, c := range b.BanCards {
+			if (id != 0 && c.CardID == id) || (name != "" && strings.EqualFold(c.CardName, name)) {
+				return c, true
+			}
+		}
+	}
+	return BannedCard{}, false
+}
+
+// getBanlistHistory retourne l'historique d'une carte (card=<id|nom>) sur
+// toutes les banlists de chaque format
+func getBanlistHistory(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	ref := strings.TrimSpace(r.URL.Query().Get("card"))
+	if ref == "" {
+		writeJSON(w, http.StatusBadRequest, APIResponse{Error: "Paramètre 'card' requis", Status: "error"})
+		return
+	}
+	lang, err := parseLang(r.URL.Query().Get("lang"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, APIResponse{Error: err.Error(), Status: "error"})
+		return
+	}
+
+	id, name, status, err := resolveHistoryCard(r, ref)
+	if err != nil {
+		writeJSON(w, status, APIResponse{Error: err.Error(), Status: "error"})
+		return
+	}
+
+	// Les identifiants des listes saisies à la main ne sont pas toujours
+	// fiables : une entrée correspond si l'identifiant ou le nom concorde
+	matches := func(c BannedCard) bool {
+		return c.CardID == id || strings.EqualFold(c.CardName, name)
+	}
+	history := CardBanHistory{CardID: id, CardName: name, Formats: make(map[string]FormatTimeline)}
+	for _, b := range banlists.all() {
+		if _, ok := history.Formats[b.Format]; !ok {
+			history.Formats[b.Format] = banlists.timeline(b.Format, matches, lang)
+		}
+	}
+	writeJSON(w, http.StatusOK, APIResponse{Data: history, Status: "success"})
+}
+
+// resolveHistoryCard identifie la carte demandée, d'abord dans les banlists
+// puis via la base de cartes, et renvoie le code HTTP adapté en cas d'erreur
+func resolveHistoryCard(r *http.Request, ref string) (int, string, int, error) {
+	id, err := strconv.Atoi(ref)
+	if err == nil {
+		if c, ok := banlists.findBannedCard(id, ""); ok {
+			return c.CardID, c.CardName, http.StatusOK, nil
+		}
+		card, err := cards.CardByID(r.Context(), id)
+		if errors.Is(err, errCardNotFound) {
+			return 0, "", http.StatusNotFound, errors.New("Carte non trouvée")
+		}
+		if err != nil {
+			return 0, "", http.StatusBadGateway, err
+		}
+		return card.ID, card.Name, http.StatusOK, nil
+	}
+
+	if c, ok := banlists.findBannedCard(0, ref); ok {
+		return c.CardID, c.CardName, http.StatusOK, nil
+	}
+	found, err := cards.SearchCards(r.Context(), CardQuery{Name: ref})
+	if err != nil {
+		return 0, "", http.StatusBadGateway, err
+	}
+	for _, card := range found {
+		if strings.EqualFold(card.Name, ref) {
+			return card.ID, card.Name, http.StatusOK, nil
+		}
+	}
+	return 0, "", http.StatusNotFound, errors.New("Carte non trouvée")
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// banlistSource fournit les cartes d'une banlist officielle avec leur
+// banlist_info. Elle est implémentée par ygoClient.
+type banlistSource interface {
+	BanlistCards(ctx context.Context, format string) ([]Card, error)
+}
+
+// banlistUpstream est la source utilisée pour importer les banlists
+var banlistUpstream banlistSource
+
+// importableFormats associe les formats importables depuis YGOProDeck à leur
+// nom canonique
+var importableFormats = map[string]string{
+	"tcg":  "TCG",
+	"ocg":  "OCG",
+	"goat": "GOAT",
+}
+
+// BanlistImport est le résultat d'un import
+type BanlistImport struct {
+	Banlist Banlist      `json:"banlist"`
+	Stored  bool         `json:"stored"`         // false si rien n'a changé depuis la liste précédente
+	Diff    *BanlistDiff `json:"diff,omitempty"` // absent pour la première liste du format
+}
+
+// snapshotBanlist construit une banlist datée à partir des cartes renvoyées
+// par YGOProDeck pour le format donné
+func snapshotBanlist(format string, found []Card, fetched time.Time) (Banlist, error) {
+	canonical := importableFormats[format]
+	date := fetched.Format("2006-01-02")
+	b := Banlist{
+		ID:          fmt.Sprintf("%s-%s-ygoprodeck", format, date),
+		BanlistName: fmt.Sprintf("%s - %s (YGOProDeck)", canonical, fetched.Format("January 2, 2006")),
+		Format:      canonical,
+		BanlistDate: date,
+		BanCards:    []BannedCard{},
+	}
+	for _, c := range found {
+		raw := c.banStatus(format)
+		if raw == "" {
+			continue
+		}
+		status, err := parseBanStatus(raw)
+		if err != nil {
+			return Banlist{}, fmt.Errorf("carte %s: %w", c.Name, err)
+		}
+		bc := BannedCard{CardName: c.Name, CardID: c.ID, BanStatus: status}
+		switch format {
+		case "tcg":
+			bc.BanTCGDate = date
+		case "ocg":
+			bc.BanOCGDate = date
+		}
+		b.BanCards = append(b.BanCards, bc)
+	}
+	sort.Slice(b.BanCards, func(i, j int) bool {
+		ci, cj := b.BanCards[i].BanStatus.Copies(), b.BanCards[j].BanStatus.Copies()
+		if ci != cj {
+			return ci < cj
+		}
+		return b.BanCards[i].CardName < b.BanCards[j].CardName
+	})
+	return b, nil
+}
+
+// previousBanlist retourne la liste du même format qui précède le snapshot,
+// en ignorant un import déjà fait le même jour
+func previousBanlist(snapshot Banlist) (Banlist, bool) {
+	for _, b := range banlists.byFormat(snapshot.Format) {
+		if b.ID != snapshot.ID && b.BanlistDate <= snapshot.BanlistDate {
+			return b, true
+		}
+	}
+	return Banlist{}, false
+}
+
+// importBanlist télécharge la banlist officielle d'un format
+// (POST ?format=tcg|ocg|goat, en-tête X-User requis), l'enregistre dans
+// DATA_DIR/banlists si elle a changé et renvoie les différences avec la liste
+// précédente
+func importBanlist(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSON(w, http.StatusMethodNotAllowed, APIResponse{Error: "Méthode non autorisée", Status: "error"})
+		return
+	}
+	if currentUser(r) == "" {
+		writeJSON(w, http.StatusUnauthorized, APIResponse{Error: "En-tête 'X-User' requis", Status: "error"})
+		return
+	}
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if _, ok := importableFormats[format]; !ok {
+		writeJSON(w, http.StatusBadRequest, APIResponse{Error: "Paramètre 'format' invalide (tcg, ocg ou goat)", Status: "error"})
+		return
+	}
+	lang, err := parseLang(r.URL.Query().Get("lang"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, APIResponse{Error: err.Error(), Status: "error"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), requestTimeout)
+	defer cancel()
+	fetched := time.Now()
+	found, err := banlistUpstream.BanlistCards(ctx, format)
+	if err != nil {
+		writeJSON(w, http.StatusBadGateway, APIResponse{Error: err.Error(), Status: "error"})
+		return
+	}
+	snapshot, err := snapshotBanlist(format, found, fetched)
+	if err != nil {
+		writeJSON(w, http.StatusBadGateway, APIResponse{Error: err.Error(), Status: "error"})
+		return
+	}
+
+	result := BanlistImport{Banlist: snapshot, Stored: true}
+	if previous, ok := previousBanlist(snapshot); ok {
+		diff := diffBanlists(previous, snapshot, lang)
+		result.Diff = &diff
+		result.Stored = diff.Hits+diff.Relaxed > 0
+	}
+	if result.Stored {
+		if err := banlists.save(snapshot); err != nil {
+			writeJSON(w, http.StatusInternalServerError, APIResponse{Error: "Enregistrement de la banlist impossible: " + err.Error(), Status: "error"})
+			return
+		}
+		log.Printf("📥 Banlist %s importée: %d cartes", snapshot.ID, len(snapshot.BanCards))
+	}
+
+	writeJSON(w, http.StatusOK, APIResponse{Data: result, Status: "success"})
+}
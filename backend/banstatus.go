@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// BanStatus est le statut d'une carte sur une banlist. Les valeurs sont
+// stables et destinées aux scripts ; les libellés affichables sont produits
+// par Label.
+type BanStatus string
+
+const (
+	Forbidden   BanStatus = "forbidden"
+	Limited     BanStatus = "limited"
+	SemiLimited BanStatus = "semi_limited"
+	Unlimited   BanStatus = "unlimited"
+)
+
+// banStatusLabels donne les libellés de chaque statut par langue
+var banStatusLabels = map[string]map[BanStatus]string{
+	"en": {Forbidden: "Forbidden", Limited: "Limited", SemiLimited: "Semi-Limited", Unlimited: "Unlimited"},
+	"fr": {Forbidden: "Interdite", Limited: "Limitée", SemiLimited: "Semi-Limitée", Unlimited: "Illimitée"},
+}
+
+// Copies retourne le nombre d'exemplaires autorisés dans un deck
+func (s BanStatus) Copies() int {
+	switch s {
+	case Forbidden:
+		return 0
+	case Limited:
+		return 1
+	case SemiLimited:
+		return 2
+	}
+	return 3
+}
+
+// Label retourne le libellé du statut dans la langue donnée (en par défaut)
+func (s BanStatus) Label(lang string) string {
+	labels, ok := banStatusLabels[lang]
+	if !ok {
+		labels = banStatusLabels["en"]
+	}
+	return labels[s]
+}
+
+// parseBanStatus accepte les valeurs machine, les libellés de YGOProDeck
+// ("Banned", "Semi-Limited") et les anciens libellés ("❌ BANNED",
+// "⚠️ LIMITED 2").
+func parseBanStatus(v string) (BanStatus, error) {
+	s := strings.ToLower(strings.TrimSpace(v))
+	switch {
+	case s == string(Forbidden), s == string(Limited), s == string(SemiLimited), s == string(Unlimited):
+		return BanStatus(s), nil
+	case strings.Contains(s, "semi"), strings.Contains(s, "limited 2"):
+		return SemiLimited, nil
+	case strings.Contains(s, "unlimited"):
+		return Unlimited, nil
+	case strings.Contains(s, "banned"), strings.Contains(s, "forbidden"):
+		return Forbidden, nil
+	case strings.Contains(s, "limited"):
+		return Limited, nil
+	}
+	return "", fmt.Errorf("statut de banlist inconnu: %q", v)
+}
+
+func (s *BanStatus) UnmarshalJSON(data []byte) error {
+	var v string
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v == "" {
+		*s = ""
+		return nil
+	}
+	status, err := parseBanStatus(v)
+	if err != nil {
+		return err
+	}
+	*s = status
+	return nil
+}
+
+// parseLang retourne la langue des libellés demandée, ou une chaîne vide si
+// aucun libellé n'est demandé
+func parseLang(lang string) (string, error) {
+	lang = strings.ToLower(lang)
+	if lang == "" {
+		return "", nil
+	}
+	if _, ok := banStatusLabels[lang]; !ok {
+		return "", fmt.Errorf("Langue non supportée: %s (en ou fr)", lang)
+	}
+	return lang, nil
+}
+
+// withLabels retourne une copie de la banlist dont chaque carte porte le
+// libellé de son statut dans la langue donnée
+func (b Banlist) withLabels(lang string) Banlist {
+	if lang == "" {
+		return b
+	}
+	cards := make([]BannedCard, len(b.BanCards))
+	for i, c := range b.BanCards {
+		c.BanLabel = c.BanStatus.Label(lang)
+		cards[i] = c
+	}
+	b.BanCards = cards
+	return b
+}
+
+func labelBanlists(lists []Banlist, lang string) []Banlist {
+	labeled := make([]Banlist, len(lists))
+	for i, b := range lists {
+		labeled[i] = b.withLabels(lang)
+	}
+	return labeled
+}
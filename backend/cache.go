@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxCacheEntries borne le nombre de réponses gardées en mémoire
+const maxCacheEntries = 2000
+
+// responseCache garde les réponses brutes de YGOProDeck en mémoire et,
+// si un dossier est fourni, sur disque pour survivre aux redémarrages.
+type responseCache struct {
+	ttl time.Duration
+	dir string
+
+	mu      sync.RWMutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	Key      string          `json:"key"`
+	StoredAt time.Time       `json:"stored_at"`
+	Body     json.RawMessage `json:"body"`
+}
+
+// newResponseCache crée un cache avec la durée de vie donnée. Un dossier vide
+// désactive la couche disque.
+func newResponseCache(ttl time.Duration, dir string) *responseCache {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			log.Printf("⚠️ Cache disque désactivé: %v", err)
+			dir = ""
+		}
+	}
+	return &responseCache{ttl: ttl, dir: dir, entries: make(map[string]cacheEntry)}
+}
+
+// cacheKey construit une clé normalisée : paramètres triés, valeurs en
+// minuscules et espaces superflus supprimés.
+func cacheKey(endpoint string, params url.Values) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(endpoint)
+	for i, k := range keys {
+		if i == 0 {
+			b.WriteByte('?')
+		} else {
+			b.WriteByte('&')
+		}
+		values := make([]string, len(params[k]))
+		for j, v := range params[k] {
+			values[j] = strings.ToLower(strings.Join(strings.Fields(v), " "))
+		}
+		b.WriteString(k + "=" + strings.Join(values, ","))
+	}
+	return b.String()
+}
+
+func (c *responseCache) get(key string) ([]byte, bool) {
+	c.mu.RLock()
+	entry, ok := c.entries[key]
+	c.mu.RUnlock()
+	if ok && time.Since(entry.StoredAt) < c.ttl {
+		return entry.Body, true
+	}
+
+	if c.dir == "" {
+		return nil, false
+	}
+	var stored cacheEntry
+	if err := readJSONFile(c.path(key), &stored); err != nil {
+		return nil, false
+	}
+	if stored.Key != key {
+		return nil, false
+	}
+	if time.Since(stored.StoredAt) >= c.ttl {
+		c.remove([]string{key})
+		return nil, false
+	}
+
+	c.mu.Lock()
+	c.entries[key] = stored
+	c.mu.Unlock()
+	return stored.Body, true
+}
+
+func (c *responseCache) set(key string, body []byte) {
+	entry := cacheEntry{Key: key, StoredAt: time.Now(), Body: body}
+
+	var pruned []string
+	c.mu.Lock()
+	if len(c.entries) >= maxCacheEntries {
+		pruned = c.pruneLocked()
+	}
+	c.entries[key] = entry
+	c.mu.Unlock()
+
+	if c.dir == "" {
+		return
+	}
+	c.remove(pruned)
+	if err := writeJSONFile(c.path(key), entry); err != nil {
+		log.Printf("⚠️ Écriture cache impossible (%s): %v", key, err)
+	}
+}
+
+// pruneLocked retire les entrées expirées, puis les plus anciennes si le cache
+// est toujours plein. Elle retourne les clés retirées, dont les fichiers sont
+// à supprimer une fois le verrou relâché.
+func (c *responseCache) pruneLocked() []string {
+	var pruned []string
+	for k, e := range c.entries {
+		if time.Since(e.StoredAt) >= c.ttl {
+			delete(c.entries, k)
+			pruned = append(pruned, k)
+		}
+	}
+	for len(c.entries) >= maxCacheEntries {
+		var oldest string
+		var oldestAt time.Time
+		for k, e := range c.entries {
+			if oldest == "" || e.StoredAt.Before(oldestAt) {
+				oldest, oldestAt = k, e.StoredAt
+			}
+		}
+		delete(c.entries, oldest)
+		pruned = append(pruned, oldest)
+	}
+	return pruned
+}
+
+// remove supprime les fichiers des clés données
+func (c *responseCache) remove(keys []string) {
+	if c.dir == "" {
+		return
+	}
+	for _, k := range keys {
+		if err := os.Remove(c.path(k)); err != nil && !os.IsNotExist(err) {
+			log.Printf("⚠️ Suppression cache impossible (%s): %v", k, err)
+		}
+	}
+}
+
+// sweep supprime du disque les fichiers expirés, y compris ceux écrits avant
+// un redémarrage et jamais relus depuis. La date de modification suffit :
+// chaque fichier est écrit au moment où l'entrée est stockée.
+func (c *responseCache) sweep() {
+	if c.dir == "" {
+		return
+	}
+	files, err := filepath.Glob(filepath.Join(c.dir, "*.json"))
+	if err != nil {
+		return
+	}
+	removed := 0
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil || time.Since(info.ModTime()) < c.ttl {
+			continue
+		}
+		if err := os.Remove(file); err == nil {
+			removed++
+		}
+	}
+	if removed > 0 {
+		log.Printf("🧹 Cache disque: %d réponses expirées supprimées", removed)
+	}
+}
+
+// run balaie le disque au démarrage puis à chaque durée de vie écoulée
+func (c *responseCache) run(ctx context.Context) {
+	c.sweep()
+	ticker := time.NewTicker(c.ttl)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.sweep()
+		}
+	}
+}
+
+func (c *responseCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// readJSONFile décode le fichier JSON à l'emplacement donné
+func readJSONFile(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// writeJSONFile écrit v de façon atomique (fichier temporaire puis rename)
+func writeJSONFile(path string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCacheKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		params   url.Values
+		want     string
+	}{
+		{"sans paramètre", "archetypes.php", nil, "archetypes.php"},
+		{"paramètres triés", "cardinfo.php", url.Values{"fname": {"dragon"}, "archetype": {"blue-eyes"}}, "cardinfo.php?archetype=blue-eyes&fname=dragon"},
+		{"minuscules et espaces", "cardinfo.php", url.Values{"fname": {"  Dark   Magician "}}, "cardinfo.php?fname=dark magician"},
+		{"valeurs multiples", "cardinfo.php", url.Values{"id": {"1", "2"}}, "cardinfo.php?id=1,2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cacheKey(tt.endpoint, tt.params); got != tt.want {
+				t.Errorf("cacheKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	a := cacheKey("cardinfo.php", url.Values{"fname": {"Ash Blossom"}, "misc": {"yes"}})
+	b := cacheKey("cardinfo.php", url.Values{"misc": {"YES"}, "fname": {"ash  blossom"}})
+	if a != b {
+		t.Errorf("requêtes équivalentes, clés différentes: %q et %q", a, b)
+	}
+}
+
+func TestResponseCacheTTL(t *testing.T) {
+	const ttl = time.Hour
+	dir := t.TempDir()
+	c := newResponseCache(ttl, dir)
+
+	c.set("fresh", []byte(`[1]`))
+	if body, ok := c.get("fresh"); !ok || string(body) != `[1]` {
+		t.Fatalf("get(fresh) = %s, %v", body, ok)
+	}
+
+	// Une entrée expirée en mémoire comme sur disque n'est plus servie
+	stale := cacheEntry{Key: "stale", StoredAt: time.Now().Add(-2 * ttl), Body: []byte(`[2]`)}
+	c.entries["stale"] = stale
+	if err := writeJSONFile(c.path("stale"), stale); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.get("stale"); ok {
+		t.Error("get(stale) a servi une entrée expirée")
+	}
+	if _, err := os.Stat(c.path("stale")); !os.IsNotExist(err) {
+		t.Error("le fichier d'une entrée expirée n'a pas été supprimé")
+	}
+
+	// Le disque survit au redémarrage
+	restarted := newResponseCache(ttl, dir)
+	if body, ok := restarted.get("fresh"); !ok || string(body) != `[1]` {
+		t.Errorf("après redémarrage, get(fresh) = %s, %v", body, ok)
+	}
+	if _, ok := restarted.get("missing"); ok {
+		t.Error("get(missing) a trouvé une entrée")
+	}
+}
+
+func TestResponseCachePrune(t *testing.T) {
+	const ttl = time.Hour
+	t.Run("expirées d'abord", func(t *testing.T) {
+		c := newResponseCache(ttl, "")
+		now := time.Now()
+		for i := 0; i < maxCacheEntries; i++ {
+			storedAt := now
+			if i%2 == 0 {
+				storedAt = now.Add(-2 * ttl)
+			}
+			c.entries[fmt.Sprint(i)] = cacheEntry{Key: fmt.Sprint(i), StoredAt: storedAt}
+		}
+		c.set("new", []byte(`[]`))
+		if want := maxCacheEntries/2 + 1; len(c.entries) != want {
+			t.Errorf("%d entrées après élagage, want %d", len(c.entries), want)
+		}
+		if _, ok := c.entries["1"]; !ok {
+			t.Error("une entrée valide a été retirée")
+		}
+	})
+
+	t.Run("puis les plus anciennes", func(t *testing.T) {
+		c := newResponseCache(ttl, "")
+		now := time.Now()
+		for i := 0; i < maxCacheEntries; i++ {
+			c.entries[fmt.Sprint(i)] = cacheEntry{Key: fmt.Sprint(i), StoredAt: now.Add(time.Duration(i) * time.Millisecond)}
+		}
+		c.set("new", []byte(`[]`))
+		if len(c.entries) != maxCacheEntries {
+			t.Errorf("%d entrées après élagage, want %d", len(c.entries), maxCacheEntries)
+		}
+		if _, ok := c.entries["0"]; ok {
+			t.Error("l'entrée la plus ancienne n'a pas été retirée")
+		}
+		if _, ok := c.entries["new"]; !ok {
+			t.Error("la nouvelle entrée est absente")
+		}
+	})
+}
+
+func TestResponseCachePruneRemovesFiles(t *testing.T) {
+	const ttl = time.Hour
+	c := newResponseCache(ttl, t.TempDir())
+	now := time.Now()
+	for i := 0; i < maxCacheEntries; i++ {
+		e := cacheEntry{Key: fmt.Sprint(i), StoredAt: now.Add(time.Duration(i) * time.Millisecond)}
+		c.entries[e.Key] = e
+		if i < 2 {
+			if err := writeJSONFile(c.path(e.Key), e); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	c.set("new", []byte(`[]`))
+	if _, err := os.Stat(c.path("0")); !os.IsNotExist(err) {
+		t.Error("le fichier de l'entrée retirée est toujours sur le disque")
+	}
+	if _, err := os.Stat(c.path("1")); err != nil {
+		t.Errorf("fichier d'une entrée conservée: %v", err)
+	}
+}
+
+func TestResponseCacheSweep(t *testing.T) {
+	const ttl = time.Hour
+	dir := t.TempDir()
+	c := newResponseCache(ttl, dir)
+	c.set("fresh", []byte(`[1]`))
+
+	// Fichier laissé par une exécution précédente, jamais relu depuis
+	old := filepath.Join(dir, "old.json")
+	if err := os.WriteFile(old, []byte(`{}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	past := time.Now().Add(-2 * ttl)
+	if err := os.Chtimes(old, past, past); err != nil {
+		t.Fatal(err)
+	}
+
+	c.sweep()
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Error("fichier expiré conservé")
+	}
+	if _, err := os.Stat(c.path("fresh")); err != nil {
+		t.Errorf("fichier valide supprimé: %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import "strings"
+
+// isMonster indique si la carte est un monstre
+func (c Card) isMonster() bool {
+	return strings.Contains(c.Type, "Monster")
+}
+
+// banStatus retourne le statut YGOProDeck de la carte pour le format donné
+// (tcg, ocg ou goat), ou une chaîne vide si elle n'est pas limitée.
+func (c Card) banStatus(format string) string {
+	if c.BanlistInfo == nil {
+		return ""
+	}
+	switch format {
+	case "tcg":
+		return c.BanlistInfo.TCG
+	case "ocg":
+		return c.BanlistInfo.OCG
+	case "goat":
+		return c.BanlistInfo.GOAT
+	}
+	return ""
+}
+
+// inSet indique si la carte a été imprimée dans un set dont le code commence
+// par le préfixe donné (ex. "LEDE" ou "LEDE-EN001").
+func (c Card) inSet(code string) bool {
+	code = strings.ToUpper(code)
+	for _, set := range c.Sets {
+		if strings.HasPrefix(strings.ToUpper(set.SetCode), code) {
+			return true
+		}
+	}
+	return false
+}
+
+// releaseDate retourne la date de sortie TCG de la carte, ou OCG à défaut
+func (c Card) releaseDate() string {
+	for _, misc := range c.MiscInfo {
+		if misc.TCGDate != "" {
+			return misc.TCGDate
+		}
+	}
+	for _, misc := range c.MiscInfo {
+		if misc.OCGDate != "" {
+			return misc.OCGDate
+		}
+	}
+	return ""
+}
+
+// isExtraDeck indique si la carte se joue dans l'Extra Deck (Fusion, Synchro,
+// Xyz ou Link, Pendule compris)
+func (c Card) isExtraDeck() bool {
+	for _, frame := range []string{"fusion", "synchro", "xyz", "link"} {
+		if strings.HasPrefix(c.FrameType, frame) {
+			return true
+		}
+	}
+	return false
+}
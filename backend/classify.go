@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sort"
+)
+
+// Poids d'un exemplaire selon la section : l'Extra Deck contient beaucoup de
+// monstres génériques et compte moins que le Main Deck
+const (
+	mainArchetypeWeight  = 1.0
+	extraArchetypeWeight = 0.5
+)
+
+// Seuils pour retenir un archétype secondaire : part minimale des cartes
+// d'archétype et nombre minimal d'exemplaires pondérés
+const (
+	minSecondaryShare  = 0.25
+	minSecondaryWeight = 3.0
+)
+
+// ArchetypeGuess est l'archétype déduit de la liste d'un deck
+type ArchetypeGuess struct {
+	Label        string           `json:"label"` // ex. "Tearlament Kashtira"
+	Primary      string           `json:"primary"`
+	Secondary    string           `json:"secondary,omitempty"`
+	Scores       []ArchetypeScore `json:"scores"`
+	UnknownCards []string         `json:"unknown_cards"`
+}
+
+// ArchetypeScore est le poids d'un archétype dans le deck
+type ArchetypeScore struct {
+	Archetype string  `json:"archetype"`
+	Weight    float64 `json:"weight"`
+	Share     float64 `json:"share"` // part des cartes d'archétype
+}
+
+// classifyDeck pondère l'archétype de chaque carte du Main et de l'Extra Deck
+// par son nombre d'exemplaires. Le Side Deck est ignoré.
+func classifyDeck(ctx context.Context, d TopDeck) (ArchetypeGuess, error) {
+	resolved, unknown, err := resolveDeckCards(ctx, d)
+	if err != nil {
+		return ArchetypeGuess{}, err
+	}
+	guess := ArchetypeGuess{Scores: []ArchetypeScore{}, UnknownCards: unknown}
+
+	weights := make(map[string]float64)
+	total := 0.0
+	for _, s := range []struct {
+		names  []string
+		weight float64
+	}{{d.MainCards, mainArchetypeWeight}, {d.ExtraCards, extraArchetypeWeight}} {
+		for _, name := range s.names {
+			if c, ok := resolved[cardKey(name)]; ok && c.Archetype != "" {
+				weights[c.Archetype] += s.weight
+				total += s.weight
+			}
+		}
+	}
+	for archetype, w := range weights {
+		guess.Scores = append(guess.Scores, ArchetypeScore{Archetype: archetype, Weight: w, Share: roundScore(w / total)})
+	}
+	sort.Slice(guess.Scores, func(i, j int) bool {
+		if guess.Scores[i].Weight != guess.Scores[j].Weight {
+			return guess.Scores[i].Weight > guess.Scores[j].Weight
+		}
+		return guess.Scores[i].Archetype < guess.Scores[j].Archetype
+	})
+
+	if len(guess.Scores) == 0 {
+		return guess, nil
+	}
+	guess.Primary = guess.Scores[0].Archetype
+	guess.Label = guess.Primary
+	if len(guess.Scores) > 1 {
+		second := guess.Scores[1]
+		if second.Share >= minSecondaryShare && second.Weight >= minSecondaryWeight {
+			guess.Secondary = second.Archetype
+			guess.Label += " " + second.Archetype
+		}
+	}
+	return guess, nil
+}
+
+// tagArchetype renseigne DeckArchtype quand il est vide. Un échec de
+// résolution des cartes laisse le deck sans archétype.
+func tagArchetype(ctx context.Context, d *TopDeck) {
+	if d.DeckArchtype != "" {
+		return
+	}
+	guess, err := classifyDeck(ctx, *d)
+	if err != nil {
+		log.Printf("⚠️ Classification du deck %q impossible: %v", d.DeckName, err)
+		return
+	}
+	d.DeckArchtype = guess.Label
+}
+
+// getDeckArchetype déduit l'archétype d'un deck existant (id) ou envoyé en
+// POST (forme TopDeck)
+func getDeckArchetype(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	d, status, err := deckFromRequest(w, r)
+	if err != nil {
+		writeJSON(w, status, APIResponse{Error: err.Error(), Status: "error"})
+		return
+	}
+	guess, err := classifyDeck(r.Context(), d)
+	if err != nil {
+		writeJSON(w, http.StatusBadGateway, APIResponse{Error: err.Error(), Status: "error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, APIResponse{Data: guess, Status: "success"})
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestClassifyDeck(t *testing.T) {
+	useFakeCards(t, &fakeCardClient{cards: []Card{
+		{Name: "Tear", Archetype: "Tearlament"},
+		{Name: "Kash", Archetype: "Kashtira"},
+		{Name: "Kash Link", Archetype: "Kashtira", FrameType: "link"},
+		{Name: "Ash"},
+	}})
+	copies := func(name string, n int) []string {
+		list := make([]string, n)
+		for i := range list {
+			list[i] = name
+		}
+		return list
+	}
+	tests := []struct {
+		name  string
+		deck  TopDeck
+		label string
+	}{
+		{"archétype pur", TopDeck{MainCards: append(copies("Tear", 10), copies("Ash", 3)...)}, "Tearlament"},
+		{"hybride", TopDeck{MainCards: append(copies("Tear", 10), copies("Kash", 4)...)}, "Tearlament Kashtira"},
+		{"secondaire trop minoritaire", TopDeck{MainCards: append(copies("Tear", 12), copies("Kash", 3)...)}, "Tearlament"},
+		{"secondaire trop peu joué", TopDeck{MainCards: append(copies("Tear", 4), copies("Kash", 2)...)}, "Tearlament"},
+		// 6 exemplaires d'Extra Deck pèsent 3 : part 3/12
+		{"Extra Deck pondéré", TopDeck{MainCards: copies("Tear", 9), ExtraCards: copies("Kash Link", 6)}, "Tearlament Kashtira"},
+		{"Side Deck ignoré", TopDeck{MainCards: copies("Tear", 5), SideCards: copies("Kash", 15)}, "Tearlament"},
+		{"égalité départagée par le nom", TopDeck{MainCards: append(copies("Tear", 3), copies("Kash", 3)...)}, "Kashtira Tearlament"},
+		{"aucun archétype", TopDeck{MainCards: copies("Ash", 3)}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := classifyDeck(context.Background(), tt.deck)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Label != tt.label {
+				t.Errorf("Label = %q, want %q (scores %+v)", got.Label, tt.label, got.Scores)
+			}
+		})
+	}
+
+	got, err := classifyDeck(context.Background(), TopDeck{MainCards: append(copies("Tear", 6), "Kash", "Kash", "Carte inventée")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ArchetypeGuess{
+		Label:   "Tearlament",
+		Primary: "Tearlament",
+		Scores: []ArchetypeScore{
+			{Archetype: "Tearlament", Weight: 6, Share: 0.75},
+			{Archetype: "Kashtira", Weight: 2, Share: 0.25},
+		},
+		UnknownCards: []string{"Carte inventée"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("classifyDeck() = %+v, want %+v", got, want)
+	}
+}
+
+func TestTagArchetype(t *testing.T) {
+	useFakeCards(t, &fakeCardClient{cards: []Card{{Name: "Tear", Archetype: "Tearlament"}}})
+	d := TopDeck{MainCards: []string{"Tear"}}
+	tagArchetype(context.Background(), &d)
+	if d.DeckArchtype != "Tearlament" {
+		t.Errorf("DeckArchtype = %q, want Tearlament", d.DeckArchtype)
+	}
+
+	// Un archétype choisi par l'utilisateur est conservé
+	d = TopDeck{DeckArchtype: "Rogue", MainCards: []string{"Tear"}}
+	tagArchetype(context.Background(), &d)
+	if d.DeckArchtype != "Rogue" {
+		t.Errorf("DeckArchtype = %q, want Rogue", d.DeckArchtype)
+	}
+
+	useFakeCards(t, &fakeCardClient{err: context.DeadlineExceeded})
+	d = TopDeck{MainCards: []string{"Tear"}}
+	tagArchetype(context.Background(), &d)
+	if d.DeckArchtype != "" {
+		t.Errorf("DeckArchtype = %q après un échec, want vide", d.DeckArchtype)
+	}
+}
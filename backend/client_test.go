@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeCardClient est un CardClient en mémoire pour tester les handlers sans
+// appeler YGOProDeck. Avec err, toutes les méthodes échouent.
+type fakeCardClient struct {
+	cards      []Card
+	archetypes []string
+	err        error
+}
+
+func (f *fakeCardClient) SearchCards(ctx context.Context, q CardQuery) ([]Card, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	var found []Card
+	for _, c := range f.cards {
+		if strings.Contains(strings.ToLower(c.Name), strings.ToLower(q.Name)) {
+			found = append(found, c)
+		}
+	}
+	return found, nil
+}
+
+func (f *fakeCardClient) CardByID(ctx context.Context, id int) (Card, error) {
+	if f.err != nil {
+		return Card{}, f.err
+	}
+	for _, c := range f.cards {
+		if c.ID == id {
+			return c, nil
+		}
+	}
+	return Card{}, errCardNotFound
+}
+
+func (f *fakeCardClient) CardByName(ctx context.Context, name string) (Card, error) {
+	if f.err != nil {
+		return Card{}, f.err
+	}
+	for _, c := range f.cards {
+		if strings.EqualFold(c.Name, name) {
+			return c, nil
+		}
+	}
+	return Card{}, errCardNotFound
+}
+
+func (f *fakeCardClient) Archetypes(ctx context.Context) ([]string, error) {
+	return f.archetypes, f.err
+}
+
+// useFakeCards remplace le client global le temps d'un test
+func useFakeCards(t *testing.T, f *fakeCardClient) {
+	t.Helper()
+	previous := cards
+	cards = f
+	t.Cleanup(func() { cards = previous })
+}
+
+func TestGetCardInfo(t *testing.T) {
+	ash := Card{ID: 14558127, Name: "Ash Blossom & Joyous Spring"}
+	tests := []struct {
+		name   string
+		client *fakeCardClient
+		query  string
+		status int
+	}{
+		{"trouvée", &fakeCardClient{cards: []Card{ash}}, "?id=14558127", http.StatusOK},
+		{"id manquant", &fakeCardClient{}, "", http.StatusBadRequest},
+		{"id invalide", &fakeCardClient{}, "?id=ash", http.StatusBadRequest},
+		{"introuvable", &fakeCardClient{cards: []Card{ash}}, "?id=1", http.StatusNotFound},
+		{"API indisponible", &fakeCardClient{err: errors.New("timeout")}, "?id=14558127", http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useFakeCards(t, tt.client)
+			rec := httptest.NewRecorder()
+			getCardInfo(rec, httptest.NewRequest(http.MethodGet, "/api/card-info"+tt.query, nil))
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tt.status, rec.Body)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var resp struct{ Data Card }
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Data.Name != ash.Name {
+				t.Errorf("carte = %q, want %q", resp.Data.Name, ash.Name)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+)
+
+// DeckComparison décrit les différences entre deux decks
+type DeckComparison struct {
+	From       DeckRef     `json:"from"`
+	To         DeckRef     `json:"to"`
+	Main       SectionDiff `json:"main"`
+	Extra      SectionDiff `json:"extra"`
+	Side       SectionDiff `json:"side"`
+	Similarity float64     `json:"similarity"` // 1 pour deux listes identiques
+}
+
+// DeckRef identifie un deck dans une comparaison
+type DeckRef struct {
+	ID       string `json:"id"`
+	DeckName string `json:"deck_name"`
+}
+
+// SectionDiff regroupe les changements d'une section
+type SectionDiff struct {
+	Added   []CardCountChange `json:"added"`   // cartes absentes de from
+	Removed []CardCountChange `json:"removed"` // cartes absentes de to
+	Changed []CardCountChange `json:"changed"` // nombre d'exemplaires modifié
+}
+
+// CardCountChange est l'évolution du nombre d'exemplaires d'une carte
+type CardCountChange struct {
+	Name  string `json:"name"`
+	From  int    `json:"from"`
+	To    int    `json:"to"`
+	Delta int    `json:"delta"`
+}
+
+// countCards compte les exemplaires par cardKey et retient le premier nom vu
+func countCards(section []string, names map[string]string) map[string]int {
+	counts := make(map[string]int)
+	for _, name := range section {
+		key := cardKey(name)
+		if _, ok := names[key]; !ok {
+			names[key] = strings.TrimSpace(name)
+		}
+		counts[key]++
+	}
+	return counts
+}
+
+// diffSection compare une section et renvoie aussi les totaux utilisés pour
+// la similarité (somme des minimums et des maximums d'exemplaires)
+func diffSection(from, to []string) (SectionDiff, int, int) {
+	names := make(map[string]string)
+	before, after := countCards(from, names), countCards(to, names)
+	diff := SectionDiff{Added: []CardCountChange{}, Removed: []CardCountChange{}, Changed: []CardCountChange{}}
+	shared, union := 0, 0
+	for _, key := range sortedKeys(names) {
+		a, b := before[key], after[key]
+		shared += min(a, b)
+		union += max(a, b)
+		change := CardCountChange{Name: names[key], From: a, To: b, Delta: b - a}
+		switch {
+		case a == b:
+		case a == 0:
+			diff.Added = append(diff.Added, change)
+		case b == 0:
+			diff.Removed = append(diff.Removed, change)
+		default:
+			diff.Changed = append(diff.Changed, change)
+		}
+	}
+	return diff, shared, union
+}
+
+// compareDecks compare deux decks section par section. La similarité est
+// l'indice de Jaccard sur les multiensembles de cartes des trois sections.
+func compareDecks(from, to TopDeck) DeckComparison {
+	c := DeckComparison{
+		From: DeckRef{ID: from.ID, DeckName: from.DeckName},
+		To:   DeckRef{ID: to.ID, DeckName: to.DeckName},
+	}
+	var shared, union int
+	for _, s := range []struct {
+		diff     *SectionDiff
+		from, to []string
+	}{
+		{&c.Main, from.MainCards, to.MainCards},
+		{&c.Extra, from.ExtraCards, to.ExtraCards},
+		{&c.Side, from.SideCards, to.SideCards},
+	} {
+		diff, sh, un := diffSection(s.from, s.to)
+		*s.diff = diff
+		shared += sh
+		union += un
+	}
+	c.Similarity = 1
+	if union > 0 {
+		c.Similarity = roundScore(float64(shared) / float64(union))
+	}
+	return c
+}
+
+// getDeckComparison compare deux decks enregistrés ou top decks (from, to)
+func getDeckComparison(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	fromID, toID := r.URL.Query().Get("from"), r.URL.Query().Get("to")
+	if fromID == "" || toID == "" {
+		writeJSON(w, http.StatusBadRequest, APIResponse{Error: "Paramètres 'from' et 'to' requis", Status: "error"})
+		return
+	}
+	from, ok := findDeck(fromID)
+	if !ok {
+		writeJSON(w, http.StatusNotFound, APIResponse{Error: "Deck non trouvé: " + fromID, Status: "error"})
+		return
+	}
+	to, ok := findDeck(toID)
+	if !ok {
+		writeJSON(w, http.StatusNotFound, APIResponse{Error: "Deck non trouvé: " + toID, Status: "error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, APIResponse{Data: compareDecks(from, to), Status: "success"})
+}
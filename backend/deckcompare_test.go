@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestDiffSection(t *testing.T) {
+	from := []string{"Ash", "Ash", "Ash", "Maxx", "Veiler", "Veiler"}
+	to := []string{"ash", "Ash", "Veiler", "Veiler", "Droll", "Droll", "Droll"}
+	got, shared, union := diffSection(from, to)
+	want := SectionDiff{
+		Added:   []CardCountChange{{Name: "Droll", From: 0, To: 3, Delta: 3}},
+		Removed: []CardCountChange{{Name: "Maxx", From: 1, To: 0, Delta: -1}},
+		Changed: []CardCountChange{{Name: "Ash", From: 3, To: 2, Delta: -1}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diffSection() = %+v, want %+v", got, want)
+	}
+	// Ash 2/3, Droll 0/3, Maxx 0/1, Veiler 2/2
+	if shared != 4 || union != 9 {
+		t.Errorf("shared, union = %d, %d, want 4, 9", shared, union)
+	}
+}
+
+func TestCompareDecks(t *testing.T) {
+	deck := TopDeck{ID: "a", DeckName: "A", MainCards: []string{"Ash", "Ash", "Maxx"}, ExtraCards: []string{"Talker"}}
+	tests := []struct {
+		name string
+		from TopDeck
+		to   TopDeck
+		want float64
+	}{
+		{"identiques", deck, deck, 1},
+		{"vides", TopDeck{}, TopDeck{}, 1},
+		// Main 2/3, Extra 0/1, Side 0/1
+		{"une carte changée par section", deck, TopDeck{MainCards: []string{"Ash", "Ash"}, SideCards: []string{"Droll"}}, 0.4},
+		{"rien en commun", deck, TopDeck{MainCards: []string{"Droll"}}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := compareDecks(tt.from, tt.to)
+			if got.Similarity != tt.want {
+				t.Errorf("Similarity = %v, want %v", got.Similarity, tt.want)
+			}
+			if got.From != (DeckRef{ID: tt.from.ID, DeckName: tt.from.DeckName}) {
+				t.Errorf("From = %+v", got.From)
+			}
+		})
+	}
+
+	got := compareDecks(deck, TopDeck{MainCards: []string{"Ash", "Ash"}, SideCards: []string{"Droll"}})
+	if len(got.Main.Removed) != 1 || len(got.Extra.Removed) != 1 || len(got.Side.Added) != 1 {
+		t.Errorf("sections mal attribuées: %+v", got)
+	}
+}
+
+func TestGetDeckComparison(t *testing.T) {
+	useTestStores(t)
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"from=ycs_miami_2026&to=asian_champ_2026", http.StatusOK},
+		{"from=ycs_miami_2026", http.StatusBadRequest},
+		{"from=ycs_miami_2026&to=inconnu", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		getDeckComparison(rec, httptest.NewRequest(http.MethodGet, "/api/decks/compare?"+tt.query, nil))
+		if rec.Code != tt.want {
+			t.Errorf("%s: statut %d, want %d", tt.query, rec.Code, tt.want)
+		}
+		if tt.want != http.StatusOK {
+			continue
+		}
+		var resp struct{ Data DeckComparison }
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.Data.From.ID != "ycs_miami_2026" || resp.Data.Similarity <= 0 || resp.Data.Similarity >= 1 {
+			t.Errorf("%s: comparaison = %+v", tt.query, resp.Data)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// maxDeckBody borne la taille d'un deck envoyé à l'API
+const maxDeckBody = 1 << 20
+
+// currentUser retourne l'utilisateur indiqué par l'en-tête X-User
+func currentUser(r *http.Request) string {
+	return strings.TrimSpace(r.Header.Get("X-User"))
+}
+
+// decodeDeck lit et valide le deck envoyé dans le corps de la requête.
+// L'identifiant et les métadonnées envoyés par le client sont ignorés.
+func decodeDeck(w http.ResponseWriter, r *http.Request) (TopDeck, error) {
+	d, err := decodeDeckCards(w, r)
+	if err != nil {
+		return TopDeck{}, err
+	}
+	d.DeckName = strings.TrimSpace(d.DeckName)
+	if d.DeckName == "" {
+		return TopDeck{}, errors.New("Champ 'deck_name' requis")
+	}
+	return d, nil
+}
+
+// decodeDeckCards lit un deck sans exiger de nom, les sections absentes
+// étant remplacées par des listes vides
+func decodeDeckCards(w http.ResponseWriter, r *http.Request) (TopDeck, error) {
+	var d TopDeck
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxDeckBody))
+	if err := dec.Decode(&d); err != nil {
+		return TopDeck{}, errors.New("Deck invalide: " + err.Error())
+	}
+	for _, section := range []*[]string{&d.MainCards, &d.ExtraCards, &d.SideCards} {
+		if *section == nil {
+			*section = []string{}
+		}
+	}
+	return d, nil
+}
+
+// deckFromRequest retourne le deck existant désigné par le paramètre id ou,
+// à défaut, le deck envoyé en POST, avec le code HTTP adapté en cas d'erreur
+func deckFromRequest(w http.ResponseWriter, r *http.Request) (TopDeck, int, error) {
+	if id := r.URL.Query().Get("id"); id != "" {
+		d, ok := findDeck(id)
+		if !ok {
+			return TopDeck{}, http.StatusNotFound, errors.New("Deck non trouvé")
+		}
+		return d, http.StatusOK, nil
+	}
+	if r.Method != http.MethodPost {
+		return TopDeck{}, http.StatusBadRequest, errors.New("Deck requis: corps POST ou paramètre 'id'")
+	}
+	d, err := decodeDeckCards(w, r)
+	if err != nil {
+		return TopDeck{}, http.StatusBadRequest, err
+	}
+	return d, http.StatusOK, nil
+}
+
+// cardKey normalise un nom de carte pour compter les exemplaires d'un deck
+func cardKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// resolveDeckCards retrouve la carte correspondant à chaque nom distinct du
+// deck, indexée par cardKey. Les noms introuvables sont renvoyés triés.
+func resolveDeckCards(ctx context.Context, d TopDeck) (map[string]Card, []string, error) {
+	resolved := make(map[string]Card)
+	unknown := []string{}
+	seen := make(map[string]bool)
+	for _, section := range [][]string{d.MainCards, d.ExtraCards, d.SideCards} {
+		for _, name := range section {
+			key := cardKey(name)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			card, err := cards.CardByName(ctx, strings.TrimSpace(name))
+			switch {
+			case errors.Is(err, errCardNotFound):
+				unknown = append(unknown, strings.TrimSpace(name))
+			case err != nil:
+				return nil, nil, err
+			default:
+				resolved[key] = card
+			}
+		}
+	}
+	sort.Strings(unknown)
+	return resolved, unknown, nil
+}
+
+// handleDecks liste les decks enregistrés (GET, filtrables par owner) ou en
+// crée un nouveau (POST) appartenant à l'utilisateur X-User. Sans
+// deck_archtype, l'archétype est déduit de la liste.
+func handleDecks(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	switch r.Method {
+	case http.MethodGet:
+		page, err := parsePagination(r.URL.Query())
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, APIResponse{Error: err.Error(), Status: "error"})
+			return
+		}
+		list := paginate(decks.list(r.URL.Query().Get("owner")), &page)
+		writeJSON(w, http.StatusOK, APIResponse{Data: list, Status: "success", Pagination: &page})
+
+	case http.MethodPost:
+		user := currentUser(r)
+		if user == "" {
+			writeJSON(w, http.StatusUnauthorized, APIResponse{Error: "En-tête 'X-User' requis", Status: "error"})
+			return
+		}
+		d, err := decodeDeck(w, r)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, APIResponse{Error: err.Error(), Status: "error"})
+			return
+		}
+		tagArchetype(r.Context(), &d)
+		created, err := decks.create(user, d)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, APIResponse{Error: "Enregistrement du deck impossible: " + err.Error(), Status: "error"})
+			return
+		}
+		writeJSON(w, http.StatusCreated, APIResponse{Data: created, Status: "success"})
+
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeJSON(w, http.StatusMethodNotAllowed, APIResponse{Error: "Méthode non autorisée", Status: "error"})
+	}
+}
+
+// handleDeck lit (GET), remplace (PUT) ou supprime (DELETE) le deck
+// /api/decks/{id}. Seul le propriétaire peut le modifier ou le supprimer.
+func handleDeck(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	id := strings.TrimPrefix(r.URL.Path, "/api/decks/")
+	if id == "" || strings.Contains(id, "/") {
+		writeJSON(w, http.StatusNotFound, APIResponse{Error: "Deck non trouvé", Status: "error"})
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		d, ok := decks.get(id)
+		if !ok {
+			writeJSON(w, http.StatusNotFound, APIResponse{Error: "Deck non trouvé", Status: "error"})
+			return
+		}
+		writeJSON(w, http.StatusOK, APIResponse{Data: d, Status: "success"})
+
+	case http.MethodPut:
+		user := currentUser(r)
+		if user == "" {
+			writeJSON(w, http.StatusUnauthorized, APIResponse{Error: "En-tête 'X-User' requis", Status: "error"})
+			return
+		}
+		// Vérifié avant l'étiquetage, qui peut interroger l'API carte par carte
+		if err := decks.checkOwner(id, user); err != nil {
+			writeDeckError(w, err)
+			return
+		}
+		d, err := decodeDeck(w, r)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, APIResponse{Error: err.Error(), Status: "error"})
+			return
+		}
+		tagArchetype(r.Context(), &d)
+		updated, err := decks.update(id, user, d)
+		if err != nil {
+			writeDeckError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, APIResponse{Data: updated, Status: "success"})
+
+	case http.MethodDelete:
+		user := currentUser(r)
+		if user == "" {
+			writeJSON(w, http.StatusUnauthorized, APIResponse{Error: "En-tête 'X-User' requis", Status: "error"})
+			return
+		}
+		if err := decks.delete(id, user); err != nil {
+			writeDeckError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, APIResponse{Data: id, Status: "success"})
+
+	default:
+		w.Header().Set("Allow", "GET, PUT, DELETE")
+		writeJSON(w, http.StatusMethodNotAllowed, APIResponse{Error: "Méthode non autorisée", Status: "error"})
+	}
+}
+
+// writeDeckError traduit une erreur du dépôt de decks en réponse HTTP
+func writeDeckError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errDeckNotFound):
+		writeJSON(w, http.StatusNotFound, APIResponse{Error: "Deck non trouvé", Status: "error"})
+	case errors.Is(err, errDeckForbidden):
+		writeJSON(w, http.StatusForbidden, APIResponse{Error: "Ce deck appartient à un autre utilisateur", Status: "error"})
+	default:
+		writeJSON(w, http.StatusInternalServerError, APIResponse{Error: "Enregistrement du deck impossible: " + err.Error(), Status: "error"})
+	}
+}
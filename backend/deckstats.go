@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strings"
+)
+
+// handTraps liste des hand traps courantes que l'heuristique sur le texte ne
+// reconnaît pas (pièges activés depuis la main, effets hors Quick Effect)
+var handTraps = map[string]bool{
+	"ash blossom & joyous spring":   true,
+	"ghost ogre & snow rabbit":      true,
+	"ghost belle & haunted mansion": true,
+	"ghost mourner & moonlit chill": true,
+	"effect veiler":                 true,
+	`maxx "c"`:                      true,
+	"nibiru, the primal being":      true,
+	"droll & lock bird":             true,
+	"d.d. crow":                     true,
+	"psy-framegear gamma":           true,
+	"dimension shifter":             true,
+	"infinite impermanence":         true,
+	"artifact lancea":               true,
+	"mulcharmy fuwalos":             true,
+	"mulcharmy purulia":             true,
+	"mulcharmy meowls":              true,
+	"dominus impulse":               true,
+}
+
+// extraMechanics associe le cadre d'une carte d'Extra Deck à son mode
+// d'invocation
+var extraMechanics = []struct{ frame, name string }{
+	{"fusion", "Fusion"},
+	{"synchro", "Synchro"},
+	{"xyz", "Xyz"},
+	{"link", "Link"},
+}
+
+// DeckStats résume la composition d'un deck. Les répartitions portent sur le
+// Main Deck, sauf ExtraMechanics ; seules les cartes retrouvées sont comptées.
+type DeckStats struct {
+	MainCount      int            `json:"main_count"`
+	ExtraCount     int            `json:"extra_count"`
+	SideCount      int            `json:"side_count"`
+	Monsters       int            `json:"monsters"`
+	Spells         int            `json:"spells"`
+	Traps          int            `json:"traps"`
+	Levels         map[int]int    `json:"levels"`
+	Attributes     map[string]int `json:"attributes"`
+	Races          map[string]int `json:"races"` // monstres uniquement
+	HandTraps      int            `json:"hand_traps"`
+	HandTrapCards  []string       `json:"hand_trap_cards"`
+	ExtraMechanics map[string]int `json:"extra_mechanics"`
+	AverageATK     float64        `json:"average_atk"` // monstres à l'ATK connue
+	UnknownCards   []string       `json:"unknown_cards"`
+}
+
+// isHandTrap indique si la carte s'utilise depuis la main pendant le tour
+// adverse : liste connue, ou monstre à Quick Effect qui se défausse ou
+// s'envoie depuis la main
+func (c Card) isHandTrap() bool {
+	if handTraps[cardKey(c.Name)] {
+		return true
+	}
+	if !c.isMonster() || c.isExtraDeck() {
+		return false
+	}
+	desc := strings.ToLower(c.Desc)
+	if !strings.Contains(desc, "(quick effect)") && !strings.Contains(desc, "during your opponent's") {
+		return false
+	}
+	for _, cost := range []string{"discard this card", "send this card from your hand", "reveal this card in your hand"} {
+		if strings.Contains(desc, cost) {
+			return true
+		}
+	}
+	return false
+}
+
+// deckStats calcule la composition d'un deck
+func deckStats(ctx context.Context, d TopDeck) (DeckStats, error) {
+	resolved, unknown, err := resolveDeckCards(ctx, d)
+	if err != nil {
+		return DeckStats{}, err
+	}
+	stats := DeckStats{
+		MainCount:      len(d.MainCards),
+		ExtraCount:     len(d.ExtraCards),
+		SideCount:      len(d.SideCards),
+		Levels:         make(map[int]int),
+		Attributes:     make(map[string]int),
+		Races:          make(map[string]int),
+		HandTrapCards:  []string{},
+		ExtraMechanics: make(map[string]int),
+		UnknownCards:   unknown,
+	}
+
+	atkTotal, atkCount := 0, 0
+	seenHandTraps := make(map[string]bool)
+	for _, name := range d.MainCards {
+		c, ok := resolved[cardKey(name)]
+		if !ok {
+			continue
+		}
+		switch {
+		case c.isMonster():
+			stats.Monsters++
+			if c.Level > 0 {
+				stats.Levels[c.Level]++
+			}
+			if c.Attribute != "" {
+				stats.Attributes[c.Attribute]++
+			}
+			if c.Race != "" {
+				stats.Races[c.Race]++
+			}
+			if c.ATK >= 0 {
+				atkTotal += c.ATK
+				atkCount++
+			}
+		case strings.Contains(c.Type, "Spell"):
+			stats.Spells++
+		case strings.Contains(c.Type, "Trap"):
+			stats.Traps++
+		}
+		if c.isHandTrap() {
+			stats.HandTraps++
+			if !seenHandTraps[c.Name] {
+				seenHandTraps[c.Name] = true
+				stats.HandTrapCards = append(stats.HandTrapCards, c.Name)
+			}
+		}
+	}
+	if atkCount > 0 {
+		stats.AverageATK = math.Round(float64(atkTotal)/float64(atkCount)*10) / 10
+	}
+
+	for _, name := range d.ExtraCards {
+		c, ok := resolved[cardKey(name)]
+		if !ok {
+			continue
+		}
+		for _, m := range extraMechanics {
+			if strings.HasPrefix(c.FrameType, m.frame) {
+				stats.ExtraMechanics[m.name]++
+				break
+			}
+		}
+	}
+	return stats, nil
+}
+
+// getDeckStats calcule la composition d'un deck existant (id) ou envoyé en
+// POST (forme TopDeck)
+func getDeckStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	d, status, err := deckFromRequest(w, r)
+	if err != nil {
+		writeJSON(w, status, APIResponse{Error: err.Error(), Status: "error"})
+		return
+	}
+	stats, err := deckStats(r.Context(), d)
+	if err != nil {
+		writeJSON(w, http.StatusBadGateway, APIResponse{Error: err.Error(), Status: "error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, APIResponse{Data: stats, Status: "success"})
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestIsHandTrap(t *testing.T) {
+	tests := []struct {
+		name string
+		card Card
+		want bool
+	}{
+		{"liste connue", Card{Name: "Ash Blossom & Joyous Spring", Type: "Tuner Effect Monster"}, true},
+		{"piège de la liste", Card{Name: "Infinite Impermanence", Type: "Trap Card"}, true},
+		{"Quick Effect depuis la main", Card{Name: "Mystic Mine Kuriboh", Type: "Effect Monster", Desc: "(Quick Effect): You can discard this card; negate that effect."}, true},
+		{"tour adverse, révélée", Card{Name: "Hand Reveal", Type: "Effect Monster", Desc: "During your opponent's Main Phase, you can reveal this card in your hand; draw 1 card."}, true},
+		{"Quick Effect sur le terrain", Card{Name: "Field Negate", Type: "Effect Monster", Desc: "(Quick Effect): You can target 1 card on the field; negate its effects."}, false},
+		{"défausse pendant son tour", Card{Name: "Search Discard", Type: "Effect Monster", Desc: "You can discard this card; add 1 Spell from your Deck to your hand."}, false},
+		{"Extra Deck", Card{Name: "Link Discard", Type: "Link Monster", FrameType: "link", Desc: "(Quick Effect): You can discard this card."}, false},
+		{"magie", Card{Name: "Pot of Greed", Type: "Spell Card", Desc: "Draw 2 cards."}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.card.isHandTrap(); got != tt.want {
+			t.Errorf("%s: isHandTrap() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDeckStats(t *testing.T) {
+	useFakeCards(t, &fakeCardClient{cards: []Card{
+		{Name: "Ash Blossom & Joyous Spring", Type: "Tuner Effect Monster", FrameType: "effect", Level: 3, Attribute: "FIRE", Race: "Zombie", ATK: 0},
+		{Name: "Blue-Eyes White Dragon", Type: "Normal Monster", FrameType: "normal", Level: 8, Attribute: "LIGHT", Race: "Dragon", ATK: 3000},
+		{Name: "Unknown ATK", Type: "Effect Monster", FrameType: "effect", Level: 4, Attribute: "DARK", Race: "Fiend", ATK: -1},
+		{Name: "Pot of Greed", Type: "Spell Card", FrameType: "spell", Race: "Normal"},
+		{Name: "Solemn Judgment", Type: "Trap Card", FrameType: "trap", Race: "Counter"},
+		{Name: "Accesscode Talker", Type: "Link Monster", FrameType: "link", Attribute: "DARK", Race: "Cyberse", ATK: 2300},
+		{Name: "Apollousa", Type: "Link Monster", FrameType: "link"},
+		{Name: "Mirrorjade", Type: "Fusion Monster", FrameType: "fusion"},
+	}})
+	deck := TopDeck{
+		MainCards: []string{
+			"Ash Blossom & Joyous Spring", "ash blossom & joyous spring", "Blue-Eyes White Dragon",
+			"Unknown ATK", "Pot of Greed", "Pot of Greed", "Solemn Judgment", "Carte inventée",
+		},
+		ExtraCards: []string{"Accesscode Talker", "Apollousa", "Mirrorjade", "Carte inventée"},
+		SideCards:  []string{"Pot of Greed"},
+	}
+
+	got, err := deckStats(context.Background(), deck)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := DeckStats{
+		MainCount:      8,
+		ExtraCount:     4,
+		SideCount:      1,
+		Monsters:       4,
+		Spells:         2,
+		Traps:          1,
+		Levels:         map[int]int{3: 2, 8: 1, 4: 1},
+		Attributes:     map[string]int{"FIRE": 2, "LIGHT": 1, "DARK": 1},
+		Races:          map[string]int{"Zombie": 2, "Dragon": 1, "Fiend": 1},
+		HandTraps:      2,
+		HandTrapCards:  []string{"Ash Blossom & Joyous Spring"},
+		ExtraMechanics: map[string]int{"Link": 2, "Fusion": 1},
+		// ATK inconnue (-1) ignorée : (0 + 0 + 3000) / 3
+		AverageATK:   1000,
+		UnknownCards: []string{"Carte inventée"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("deckStats() = %+v\nwant %+v", got, want)
+	}
+
+	useFakeCards(t, &fakeCardClient{err: context.DeadlineExceeded})
+	if _, err := deckStats(context.Background(), deck); err == nil {
+		t.Error("erreur de l'API ignorée")
+	}
+}
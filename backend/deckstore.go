@@ -0,0 +1,180 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	errDeckNotFound  = errors.New("deck non trouvé")
+	errDeckForbidden = errors.New("ce deck appartient à un autre utilisateur")
+)
+
+// decks est le dépôt de decks utilisé par les handlers
+var decks *deckStore
+
+// deckStore enregistre les decks de l'équipe dans un fichier JSON. Chaque
+// modification réécrit le fichier entier, ce qui reste rapide pour quelques
+// milliers de decks.
+type deckStore struct {
+	path string
+
+	mu    sync.RWMutex
+	decks map[string]TopDeck
+}
+
+func newDeckStore(path string) *deckStore {
+	return &deckStore{path: path, decks: make(map[string]TopDeck)}
+}
+
+// load lit le fichier des decks, s'il existe
+func (s *deckStore) load() error {
+	var list []TopDeck
+	if err := readJSONFile(s.path, &list); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, d := range list {
+		s.decks[d.ID] = d
+	}
+	return nil
+}
+
+// list retourne les decks, éventuellement ceux d'un seul propriétaire, du plus
+// récemment modifié au plus ancien
+func (s *deckStore) list(owner string) []TopDeck {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	list := []TopDeck{}
+	for _, d := range s.decks {
+		if owner == "" || d.Owner == owner {
+			list = append(list, d)
+		}
+	}
+	sortDecks(list)
+	return list
+}
+
+// get retourne le deck portant l'identifiant donné
+func (s *deckStore) get(id string) (TopDeck, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	d, ok := s.decks[id]
+	return d, ok
+}
+
+// create enregistre un nouveau deck appartenant à owner
+func (s *deckStore) create(owner string, d TopDeck) (TopDeck, error) {
+	id, err := newRecordID()
+	if err != nil {
+		return TopDeck{}, err
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	d.ID, d.Owner, d.CreatedAt, d.UpdatedAt = id, owner, now, now
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.decks[id] = d
+	if err := s.persistLocked(); err != nil {
+		delete(s.decks, id)
+		return TopDeck{}, err
+	}
+	return d, nil
+}
+
+// checkOwner vérifie que le deck existe et appartient à owner
+func (s *deckStore) checkOwner(id, owner string) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	d, ok := s.decks[id]
+	if !ok {
+		return errDeckNotFound
+	}
+	if d.Owner != owner {
+		return errDeckForbidden
+	}
+	return nil
+}
+
+// update remplace le contenu d'un deck. Seul son propriétaire peut le modifier.
+func (s *deckStore) update(id, owner string, d TopDeck) (TopDeck, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, ok := s.decks[id]
+	if !ok {
+		return TopDeck{}, errDeckNotFound
+	}
+	if old.Owner != owner {
+		return TopDeck{}, errDeckForbidden
+	}
+	d.ID, d.Owner, d.CreatedAt = id, old.Owner, old.CreatedAt
+	d.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+
+	s.decks[id] = d
+	if err := s.persistLocked(); err != nil {
+		s.decks[id] = old
+		return TopDeck{}, err
+	}
+	return d, nil
+}
+
+// delete supprime un deck. Seul son propriétaire peut le supprimer.
+func (s *deckStore) delete(id, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, ok := s.decks[id]
+	if !ok {
+		return errDeckNotFound
+	}
+	if old.Owner != owner {
+		return errDeckForbidden
+	}
+	delete(s.decks, id)
+	if err := s.persistLocked(); err != nil {
+		s.decks[id] = old
+		return err
+	}
+	return nil
+}
+
+// persistLocked réécrit le fichier ; s.mu doit être verrouillé
+func (s *deckStore) persistLocked() error {
+	list := make([]TopDeck, 0, len(s.decks))
+	for _, d := range s.decks {
+		list = append(list, d)
+	}
+	sortDecks(list)
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	return writeJSONFile(s.path, list)
+}
+
+// sortDecks trie du plus récemment modifié au plus ancien
+func sortDecks(list []TopDeck) {
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].UpdatedAt != list[j].UpdatedAt {
+			return list[i].UpdatedAt > list[j].UpdatedAt
+		}
+		return list[i].ID < list[j].ID
+	})
+}
+
+// newRecordID génère un identifiant aléatoire de 16 caractères hexadécimaux
+func newRecordID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Règles de construction d'un deck
+const (
+	minMainDeck = 40
+	maxMainDeck = 60
+	maxExtra    = 15
+	maxSide     = 15
+	maxCopies   = 3
+)
+
+// DeckViolation est une règle de construction non respectée
+type DeckViolation struct {
+	Rule    string    `json:"rule"` // main_size, extra_size, side_size, copies, banlist, extra_in_main, main_in_extra
+	Card    string    `json:"card,omitempty"`
+	Count   int       `json:"count"`
+	Limit   int       `json:"limit"`
+	Status  BanStatus `json:"ban_status,omitempty"`
+	Message string    `json:"message"`
+}
+
+// DeckValidation est le résultat de la vérification d'un deck
+type DeckValidation struct {
+	Legal        bool            `json:"legal"`
+	Banlist      BanlistRef      `json:"banlist"`
+	Violations   []DeckViolation `json:"violations"`
+	UnknownCards []string        `json:"unknown_cards"` // type non vérifié faute de données
+}
+
+// validateDeck vérifie les tailles de sections, le nombre d'exemplaires, les
+// limites de la banlist et la section de chaque carte
+func validateDeck(ctx context.Context, d TopDeck, banlist Banlist) (DeckValidation, error) {
+	result := DeckValidation{Banlist: refOf(banlist), Violations: []DeckViolation{}, UnknownCards: []string{}}
+	add := func(v DeckViolation) { result.Violations = append(result.Violations, v) }
+
+	sizes := []struct {
+		rule, section string
+		count         int
+		min, max      int
+	}{
+		{"main_size", "Main Deck", len(d.MainCards), minMainDeck, maxMainDeck},
+		{"extra_size", "Extra Deck", len(d.ExtraCards), 0, maxExtra},
+		{"side_size", "Side Deck", len(d.SideCards), 0, maxSide},
+	}
+	for _, s := range sizes {
+		switch {
+		case s.count < s.min:
+			add(DeckViolation{Rule: s.rule, Count: s.count, Limit: s.min,
+				Message: fmt.Sprintf("%s: %d cartes, minimum %d", s.section, s.count, s.min)})
+		case s.count > s.max:
+			add(DeckViolation{Rule: s.rule, Count: s.count, Limit: s.max,
+				Message: fmt.Sprintf("%s: %d cartes, maximum %d", s.section, s.count, s.max)})
+		}
+	}
+
+	resolved, unknown, err := resolveDeckCards(ctx, d)
+	if err != nil {
+		return DeckValidation{}, err
+	}
+	result.UnknownCards = unknown
+
+	// Exemplaires comptés sur les trois sections, noms comparés sans la casse
+	counts := make(map[string]int)
+	names := make(map[string]string)
+	for _, section := range [][]string{d.MainCards, d.ExtraCards, d.SideCards} {
+		for _, name := range section {
+			key := cardKey(name)
+			if _, ok := names[key]; !ok {
+				names[key] = strings.TrimSpace(name)
+			}
+			counts[key]++
+		}
+	}
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if n := counts[key]; n > maxCopies {
+			add(DeckViolation{Rule: "copies", Card: names[key], Count: n, Limit: maxCopies,
+				Message: fmt.Sprintf("%s: %d exemplaires, maximum %d", names[key], n, maxCopies)})
+		}
+	}
+	for _, key := range keys {
+		status, ok := banlistStatus(banlist, names[key], resolved[key].ID)
+		if n := counts[key]; ok && n > status.Copies() {
+			add(DeckViolation{Rule: "banlist", Card: names[key], Count: n, Limit: status.Copies(), Status: status,
+				Message: fmt.Sprintf("%s est %s sur %s: %d exemplaire(s) autorisé(s), %d présent(s)",
+					names[key], status.Label("fr"), banlist.BanlistName, status.Copies(), n)})
+		}
+	}
+
+	misplaced := func(section []string, wantExtra bool, rule, message string) {
+		seen := make(map[string]bool)
+		for _, name := range section {
+			key := cardKey(name)
+			card, ok := resolved[key]
+			if !ok || seen[key] || card.isExtraDeck() == wantExtra {
+				continue
+			}
+			seen[key] = true
+			add(DeckViolation{Rule: rule, Card: names[key], Count: countOf(section, key),
+				Message: fmt.Sprintf("%s (%s) %s", names[key], card.Type, message)})
+		}
+	}
+	misplaced(d.MainCards, false, "extra_in_main", "ne peut pas être dans le Main Deck")
+	misplaced(d.ExtraCards, true, "main_in_extra", "ne peut pas être dans l'Extra Deck")
+
+	result.Legal = len(result.Violations) == 0
+	return result, nil
+}
+
+// banlistStatus retourne le statut d'une carte sur la banlist, retrouvée par
+// son identifiant ou par son nom
+func banlistStatus(b Banlist, name string, id int) (BanStatus, bool) {
+	for _, c := range b.BanCards {
+		if (id != 0 && c.CardID == id) || strings.EqualFold(c.CardName, name) {
+			return c.BanStatus, true
+		}
+	}
+	return "", false
+}
+
+func countOf(section []string, key string) int {
+	n := 0
+	for _, name := range section {
+		if cardKey(name) == key {
+			n++
+		}
+	}
+	return n
+}
+
+// validateDeckHandler vérifie la légalité d'un deck envoyé en POST (forme
+// TopDeck) ou d'un deck existant (id) sur la banlist du format (TCG par
+// défaut) en vigueur à la date donnée (aujourd'hui par défaut)
+func validateDeckHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "tcg"
+	}
+	canonical, ok := normalizeFormat(format)
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, APIResponse{Error: "Format inconnu: " + format, Status: "error"})
+		return
+	}
+	date := r.URL.Query().Get("date")
+	if date == "" {
+		date = time.Now().Format("2006-01-02")
+	} else if _, err := time.Parse("2006-01-02", date); err != nil {
+		writeJSON(w, http.StatusBadRequest, APIResponse{Error: "Paramètre 'date' invalide (AAAA-MM-JJ): " + date, Status: "error"})
+		return
+	}
+
+	d, status, err := deckFromRequest(w, r)
+	if err != nil {
+		writeJSON(w, status, APIResponse{Error: err.Error(), Status: "error"})
+		return
+	}
+
+	banlist, ok := banlists.effective(canonical, date)
+	if !ok {
+		writeJSON(w, http.StatusNotFound, APIResponse{Error: fmt.Sprintf("Aucune banlist %s en vigueur le %s", canonical, date), Status: "error"})
+		return
+	}
+	result, err := validateDeck(r.Context(), d, banlist)
+	if err != nil {
+		writeJSON(w, http.StatusBadGateway, APIResponse{Error: err.Error(), Status: "error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, APIResponse{Data: result, Status: "success"})
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// distinctCards retourne n noms de cartes tous différents
+func distinctCards(prefix string, n int) []string {
+	names := make([]string, n)
+	for i := range names {
+		names[i] = fmt.Sprintf("%s %d", prefix, i+1)
+	}
+	return names
+}
+
+func TestValidateDeck(t *testing.T) {
+	useFakeCards(t, &fakeCardClient{cards: []Card{
+		{ID: 14558127, Name: "Ash Blossom & Joyous Spring", FrameType: "effect"},
+		{ID: 55144522, Name: "Pot of Greed", FrameType: "spell"},
+		{ID: 23434538, Name: "Maxx \"C\"", FrameType: "effect"},
+		{ID: 86066372, Name: "Accesscode Talker", FrameType: "link"},
+	}})
+	banlist := Banlist{BanlistName: "TCG test", BanCards: []BannedCard{
+		{CardName: "Pot of Greed", CardID: 55144522, BanStatus: Forbidden},
+		// Retrouvée par son code malgré un nom différent
+		{CardName: "Maxx C", CardID: 23434538, BanStatus: Limited},
+	}}
+
+	main := func(extra ...string) []string {
+		return append(distinctCards("Main", 40-len(extra)), extra...)
+	}
+	tests := []struct {
+		name  string
+		deck  TopDeck
+		rules []string
+	}{
+		{"deck légal", TopDeck{MainCards: main("Ash Blossom & Joyous Spring"), ExtraCards: []string{"Accesscode Talker"}}, nil},
+		{"Main Deck trop petit", TopDeck{MainCards: distinctCards("Main", 39)}, []string{"main_size"}},
+		{"Main Deck trop grand", TopDeck{MainCards: distinctCards("Main", 61)}, []string{"main_size"}},
+		{"Extra Deck trop grand", TopDeck{MainCards: main(), ExtraCards: distinctCards("Extra", 16)}, []string{"extra_size"}},
+		{"Side Deck trop grand", TopDeck{MainCards: main(), SideCards: distinctCards("Side", 16)}, []string{"side_size"}},
+		{"exemplaires comptés sur toutes les sections", TopDeck{MainCards: main("Ash Blossom & Joyous Spring", "ash blossom & joyous spring", "Ash Blossom & Joyous Spring"), SideCards: []string{"ASH BLOSSOM & JOYOUS SPRING"}}, []string{"copies"}},
+		{"carte interdite", TopDeck{MainCards: main("Pot of Greed")}, []string{"banlist"}},
+		{"limitée retrouvée par son code", TopDeck{MainCards: main("Maxx \"C\"", "Maxx \"C\"")}, []string{"banlist"}},
+		{"Extra Deck dans le Main Deck", TopDeck{MainCards: main("Accesscode Talker")}, []string{"extra_in_main"}},
+		{"Main Deck dans l'Extra Deck", TopDeck{MainCards: main(), ExtraCards: []string{"Ash Blossom & Joyous Spring"}}, []string{"main_in_extra"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := validateDeck(context.Background(), tt.deck, banlist)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var rules []string
+			for _, v := range got.Violations {
+				rules = append(rules, v.Rule)
+			}
+			if !reflect.DeepEqual(rules, tt.rules) || got.Legal != (len(tt.rules) == 0) {
+				t.Errorf("legal = %v, règles = %v, want %v", got.Legal, rules, tt.rules)
+			}
+		})
+	}
+}
+
+func TestValidateDeckViolation(t *testing.T) {
+	useFakeCards(t, &fakeCardClient{cards: []Card{{ID: 55144522, Name: "Pot of Greed", FrameType: "spell"}}})
+	banlist := Banlist{BanlistName: "TCG test", BanCards: []BannedCard{{CardName: "Pot of Greed", CardID: 55144522, BanStatus: Forbidden}}}
+	deck := TopDeck{MainCards: append(distinctCards("Main", 39), "Pot of Greed")}
+
+	got, err := validateDeck(context.Background(), deck, banlist)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := DeckViolation{Rule: "banlist", Card: "Pot of Greed", Count: 1, Limit: 0, Status: Forbidden}
+	if len(got.Violations) != 1 {
+		t.Fatalf("violations = %+v", got.Violations)
+	}
+	v := got.Violations[0]
+	v.Message = ""
+	if v != want {
+		t.Errorf("violation = %+v, want %+v", v, want)
+	}
+	if len(got.UnknownCards) != 39 {
+		t.Errorf("%d cartes inconnues, want 39", len(got.UnknownCards))
+	}
+
+	useFakeCards(t, &fakeCardClient{err: errCardNotFound})
+	if _, err := validateDeck(context.Background(), deck, banlist); err != nil {
+		t.Errorf("cartes introuvables traitées comme une erreur: %v", err)
+	}
+	useFakeCards(t, &fakeCardClient{err: context.DeadlineExceeded})
+	if _, err := validateDeck(context.Background(), deck, banlist); err == nil {
+		t.Error("erreur de l'API ignorée")
+	}
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"sync/atomic"
+	"unicode"
+)
+
+// Seuils de la recherche approchée
+const (
+	minFuzzyScore    = 0.5
+	maxFuzzyCompared = 300
+	maxSuggestions   = 5
+)
+
+// cardNames est l'index de noms utilisé pour la recherche approchée
+var cardNames atomic.Pointer[fuzzyIndex]
+
+// fuzzyIndex retrouve des noms de cartes malgré les fautes de frappe. Les
+// candidats sont présélectionnés par trigrammes communs puis classés par
+// distance d'édition mot à mot.
+type fuzzyIndex struct {
+	cards    []Card
+	names    []string // noms normalisés
+	trigrams map[string][]int
+}
+
+// fuzzyMatch est une carte trouvée avec son score de similarité (0 à 1)
+type fuzzyMatch struct {
+	Card
+	Score float64 `json:"score"`
+}
+
+// nameSuggestion est une suggestion "vouliez-vous dire" légère
+type nameSuggestion struct {
+	ID    int     `json:"id"`
+	Name  string  `json:"name"`
+	Score float64 `json:"score"`
+}
+
+func buildFuzzyIndex(cards []Card) *fuzzyIndex {
+	f := &fuzzyIndex{cards: cards, names: make([]string, len(cards)), trigrams: make(map[string][]int)}
+	for i, c := range cards {
+		f.names[i] = normalizeName(c.Name)
+		for _, tri := range trigrams(f.names[i]) {
+			f.trigrams[tri] = append(f.trigrams[tri], i)
+		}
+	}
+	return f
+}
+
+// normalizeName passe en minuscules et remplace la ponctuation par des espaces
+func normalizeName(name string) string {
+	var b strings.Builder
+	space := true
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			space = false
+		} else if !space {
+			b.WriteByte(' ')
+			space = true
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// trigrams retourne l'ensemble des trigrammes du texte, bordé d'espaces
+func trigrams(s string) []string {
+	runes := []rune("  " + s + " ")
+	seen := make(map[string]bool)
+	var out []string
+	for i := 0; i+3 <= len(runes); i++ {
+		tri := string(runes[i : i+3])
+		if !seen[tri] {
+			seen[tri] = true
+			out = append(out, tri)
+		}
+	}
+	return out
+}
+
+// search retourne au plus limit cartes dont le nom ressemble à la requête,
+// par score décroissant
+func (f *fuzzyIndex) search(query string, limit int) []fuzzyMatch {
+	query = normalizeName(query)
+	if query == "" {
+		return nil
+	}
+	qTrigrams := trigrams(query)
+
+	shared := make(map[int]int)
+	for _, tri := range qTrigrams {
+		for _, doc := range f.trigrams[tri] {
+			shared[doc]++
+		}
+	}
+	candidates := make([]int, 0, len(shared))
+	for doc, n := range shared {
+		if n*3 >= len(qTrigrams) {
+			candidates = append(candidates, doc)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if shared[candidates[i]] != shared[candidates[j]] {
+			return shared[candidates[i]] > shared[candidates[j]]
+		}
+		return candidates[i] < candidates[j]
+	})
+	if len(candidates) > maxFuzzyCompared {
+		candidates = candidates[:maxFuzzyCompared]
+	}
+
+	qWords := strings.Fields(query)
+	var matches []fuzzyMatch
+	for _, doc := range candidates {
+		trigramScore := float64(shared[doc]) / float64(len(qTrigrams))
+		score := 0.5*trigramScore + 0.5*wordSimilarity(qWords, strings.Fields(f.names[doc]))
+		// À score égal, préférer les noms de longueur proche de la requête
+		score *= 0.9 + 0.1*lengthRatio(query, f.names[doc])
+		if score >= minFuzzyScore {
+			matches = append(matches, fuzzyMatch{Card: f.cards[doc], Score: roundScore(score)})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Name < matches[j].Name
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// suggest retourne les meilleurs noms proches de la requête
+func (f *fuzzyIndex) suggest(query string) []nameSuggestion {
+	matches := f.search(query, maxSuggestions)
+	suggestions := make([]nameSuggestion, len(matches))
+	for i, m := range matches {
+		suggestions[i] = nameSuggestion{ID: m.ID, Name: m.Name, Score: m.Score}
+	}
+	return suggestions
+}
+
+// wordSimilarity associe chaque mot de la requête au mot le plus proche du nom
+// et retourne la similarité moyenne
+func wordSimilarity(query, name []string) float64 {
+	if len(query) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, qw := range query {
+		best := 0.0
+		for _, nw := range name {
+			if s := editSimilarity(qw, nw); s > best {
+				best = s
+			}
+		}
+		total += best
+	}
+	return total / float64(len(query))
+}
+
+// editSimilarity vaut 1 pour deux mots identiques et décroît avec la distance
+func editSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(editDistance(ra, rb))/float64(longest)
+}
+
+// editDistance calcule la distance de Damerau-Levenshtein restreinte
+// (insertion, suppression, substitution et transposition de lettres voisines)
+func editDistance(a, b []rune) int {
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(b)]
+}
+
+func lengthRatio(a, b string) float64 {
+	la, lb := len([]rune(a)), len([]rune(b))
+	if la > lb {
+		la, lb = lb, la
+	}
+	if lb == 0 {
+		return 1
+	}
+	return float64(la) / float64(lb)
+}
+
+func roundScore(s float64) float64 {
+	return float64(int(s*1000+0.5)) / 1000
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Limites d'une règle : au-delà, l'analyse récursive risquerait d'épuiser la
+// pile de la goroutine
+const (
+	maxExprLength = 1000
+	maxExprDepth  = 32
+)
+
+// handExpr est une règle de main jouable compilée, par exemple
+// `starters >= 1 && (handtraps >= 1 || "Maxx \"C\"") && !bricks`. Un groupe ou
+// une carte sans comparaison signifie "au moins un exemplaire".
+type handExpr interface {
+	eval(counts []int) bool
+}
+
+type orExpr struct{ left, right handExpr }
+type andExpr struct{ left, right handExpr }
+type notExpr struct{ inner handExpr }
+
+// cmpExpr compare le nombre de cartes d'un terme (groupe ou carte) à n
+type cmpExpr struct {
+	term int
+	op   string
+	n    int
+}
+
+func (e orExpr) eval(c []int) bool  { return e.left.eval(c) || e.right.eval(c) }
+func (e andExpr) eval(c []int) bool { return e.left.eval(c) && e.right.eval(c) }
+func (e notExpr) eval(c []int) bool { return !e.inner.eval(c) }
+
+func (e cmpExpr) eval(c []int) bool {
+	n := c[e.term]
+	switch e.op {
+	case ">=":
+		return n >= e.n
+	case "<=":
+		return n <= e.n
+	case ">":
+		return n > e.n
+	case "<":
+		return n < e.n
+	case "==":
+		return n == e.n
+	}
+	return n != e.n
+}
+
+// handTerms recense les termes utilisés par les règles : d'abord les groupes
+// nommés, puis les cartes citées entre guillemets (clé cardKey). Groupes et
+// cartes ont chacun leur espace de noms : "Ash" reste une carte même si un
+// groupe s'appelle ash.
+type handTerms struct {
+	groups map[string]int
+	cards  map[string]int
+	count  int
+}
+
+func newHandTerms(groups []string) *handTerms {
+	t := &handTerms{groups: make(map[string]int), cards: make(map[string]int)}
+	for _, g := range groups {
+		t.groups[g] = t.count
+		t.count++
+	}
+	return t
+}
+
+// card retourne le terme de la carte, en le créant au besoin
+func (t *handTerms) card(key string) int {
+	if i, ok := t.cards[key]; ok {
+		return i
+	}
+	t.cards[key] = t.count
+	t.count++
+	return t.cards[key]
+}
+
+// exprParser est un analyseur descendant récursif :
+//
+//	expr    = and { ("||" | "or") and }
+//	and     = unary { ("&&" | "and") unary }
+//	unary   = ("!" | "not") unary | primary
+//	primary = "(" expr ")" | term [ op entier ]
+//	term    = groupe | "nom de carte"
+type exprParser struct {
+	src   string
+	pos   int
+	depth int
+	terms *handTerms
+}
+
+// parseHandExpr compile une règle en enregistrant ses termes dans terms
+func parseHandExpr(src string, terms *handTerms) (handExpr, error) {
+	if len(src) > maxExprLength {
+		return nil, fmt.Errorf("règle trop longue (%d caractères au maximum)", maxExprLength)
+	}
+	p := &exprParser{src: src, terms: terms}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.src) {
+		return nil, fmt.Errorf("caractère inattendu en position %d: %q", p.pos+1, p.src[p.pos:])
+	}
+	return e, nil
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.src) && unicode.IsSpace(rune(p.src[p.pos])) {
+		p.pos++
+	}
+}
+
+// accept consomme l'un des symboles donnés s'il suit. Les mots-clés doivent
+// être suivis d'un séparateur.
+func (p *exprParser) accept(tokens ...string) bool {
+	p.skipSpace()
+	for _, tok := range tokens {
+		if !strings.HasPrefix(p.src[p.pos:], tok) {
+			continue
+		}
+		end := p.pos + len(tok)
+		if isIdentByte(tok[0]) && end < len(p.src) && isIdentByte(p.src[end]) {
+			continue
+		}
+		p.pos = end
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) parseOr() (handExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||", "or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orExpr{left, right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (handExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&", "and") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andExpr{left, right}
+	}
+	return left, nil
+}
+
+// enter compte un niveau d'imbrication et refuse les règles trop profondes
+func (p *exprParser) enter() error {
+	p.depth++
+	if p.depth > maxExprDepth {
+		return fmt.Errorf("règle trop imbriquée en position %d (%d niveaux au maximum)", p.pos+1, maxExprDepth)
+	}
+	return nil
+}
+
+func (p *exprParser) parseUnary() (handExpr, error) {
+	// "!=" n'est jamais en tête d'un opérande
+	if p.accept("not") || p.accept("!") {
+		if err := p.enter(); err != nil {
+			return nil, err
+		}
+		defer func() { p.depth-- }()
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{inner}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (handExpr, error) {
+	if p.accept("(") {
+		if err := p.enter(); err != nil {
+			return nil, err
+		}
+		defer func() { p.depth-- }()
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, fmt.Errorf("parenthèse fermante attendue en position %d", p.pos+1)
+		}
+		return e, nil
+	}
+
+	term, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{">=", "<=", "==", "!=", ">", "<"} {
+		if !p.accept(op) {
+			continue
+		}
+		p.skipSpace()
+		start := p.pos
+		for p.pos < len(p.src) && p.src[p.pos] >= '0' && p.src[p.pos] <= '9' {
+			p.pos++
+		}
+		n, err := strconv.Atoi(p.src[start:p.pos])
+		if err != nil {
+			return nil, fmt.Errorf("nombre attendu après %s en position %d", op, start+1)
+		}
+		return cmpExpr{term: term, op: op, n: n}, nil
+	}
+	return cmpExpr{term: term, op: ">=", n: 1}, nil
+}
+
+func (p *exprParser) parseTerm() (int, error) {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return 0, fmt.Errorf("groupe ou carte attendu en fin de règle")
+	}
+	if p.src[p.pos] == '"' {
+		var name strings.Builder
+		for p.pos++; p.pos < len(p.src); p.pos++ {
+			switch c := p.src[p.pos]; {
+			case c == '\\' && p.pos+1 < len(p.src):
+				p.pos++
+				name.WriteByte(p.src[p.pos])
+			case c == '"':
+				p.pos++
+				return p.terms.card(cardKey(name.String())), nil
+			default:
+				name.WriteByte(c)
+			}
+		}
+		return 0, fmt.Errorf("guillemet fermant attendu")
+	}
+
+	start := p.pos
+	for p.pos < len(p.src) && isIdentByte(p.src[p.pos]) {
+		p.pos++
+	}
+	group := p.src[start:p.pos]
+	if group == "" {
+		return 0, fmt.Errorf("groupe ou carte attendu en position %d", start+1)
+	}
+	i, ok := p.terms.groups[group]
+	if !ok {
+		return 0, fmt.Errorf("groupe inconnu: %s", group)
+	}
+	return i, nil
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '-' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseHandExpr(t *testing.T) {
+	// Clés : nom du groupe, ou "card:" suivi de la clé cardKey de la carte
+	type hand map[string]int
+	tests := []struct {
+		expr string
+		hand hand
+		want bool
+	}{
+		{"starters", hand{"starters": 1}, true},
+		{"starters", hand{}, false},
+		{"starters >= 2", hand{"starters": 1}, false},
+		{"starters == 2", hand{"starters": 2}, true},
+		{"bricks <= 1", hand{"bricks": 2}, false},
+		{"bricks != 0", hand{"bricks": 0}, false},
+		{"starters > 1 || handtraps < 1", hand{"starters": 1, "handtraps": 0}, true},
+		{"starters && !bricks", hand{"starters": 1, "bricks": 1}, false},
+		{"starters and not bricks", hand{"starters": 1}, true},
+		{"starters && (handtraps || bricks)", hand{"starters": 1, "bricks": 1}, true},
+		{"!(starters || handtraps)", hand{"handtraps": 1}, false},
+		{`"Maxx \"C\"" && starters`, hand{`card:maxx "c"`: 1, "starters": 1}, true},
+		{`"Ash Blossom & Joyous Spring" >= 2`, hand{"card:ash blossom & joyous spring": 1}, false},
+		// && est prioritaire sur ||
+		{"starters || handtraps && bricks", hand{"starters": 1}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			terms := newHandTerms([]string{"bricks", "handtraps", "starters"})
+			e, err := parseHandExpr(tt.expr, terms)
+			if err != nil {
+				t.Fatalf("parseHandExpr(%q): %v", tt.expr, err)
+			}
+			counts := make([]int, terms.count)
+			for name, i := range terms.groups {
+				counts[i] = tt.hand[name]
+			}
+			for key, i := range terms.cards {
+				counts[i] = tt.hand["card:"+key]
+			}
+			if got := e.eval(counts); got != tt.want {
+				t.Errorf("eval(%v) = %v, want %v", tt.hand, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseHandExprErrors(t *testing.T) {
+	tests := []struct{ name, expr string }{
+		{"vide", ""},
+		{"groupe inconnu", "engine"},
+		{"opérateur sans nombre", "starters >="},
+		{"parenthèse non fermée", "(starters"},
+		{"guillemet non fermé", `"Ash Blossom`},
+		{"reste inattendu", "starters bricks"},
+		{"trop imbriquée", strings.Repeat("(", maxExprDepth+1) + "starters" + strings.Repeat(")", maxExprDepth+1)},
+		{"trop de négations", strings.Repeat("!", maxExprDepth+1) + "starters"},
+		{"trop longue", strings.Repeat("(", 1<<20) + "starters"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseHandExpr(tt.expr, newHandTerms([]string{"bricks", "starters"})); err == nil {
+				t.Errorf("parseHandExpr(%.40q) sans erreur", tt.expr)
+			}
+		})
+	}
+
+	deep := strings.Repeat("(", maxExprDepth) + "starters" + strings.Repeat(")", maxExprDepth)
+	if _, err := parseHandExpr(deep, newHandTerms([]string{"starters"})); err != nil {
+		t.Errorf("imbrication maximale refusée: %v", err)
+	}
+}
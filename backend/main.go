@@ -1,15 +1,19 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
-	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 )
 
 const (
@@ -23,18 +27,74 @@ func getPort() string {
 	return ":8080"
 }
 
+// getAPIBase retourne l'URL de l'API YGOProDeck, surchargeable pour les tests
+func getAPIBase() string {
+	if base := os.Getenv("YGOPRODECK_API_BASE"); base != "" {
+		return strings.TrimSuffix(base, "/")
+	}
+	return ygoprodeckAPIBase
+}
+
+// getMirrorRefresh retourne l'intervalle de vérification de la version amont
+func getMirrorRefresh() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("MIRROR_REFRESH")); err == nil && d > 0 {
+		return d
+	}
+	return 6 * time.Hour
+}
+
+// getBanlistDir retourne le dossier des banlists livrées avec le code. Les
+// banlists importées sont écrites dans DATA_DIR/banlists.
+func getBanlistDir() string {
+	if dir := os.Getenv("BANLIST_DIR"); dir != "" {
+		return dir
+	}
+	if _, err := os.Stat("banlists"); os.IsNotExist(err) {
+		return filepath.Join("backend", "banlists")
+	}
+	return "banlists"
+}
+
+// getDataDir retourne le dossier où le serveur persiste ses données
+func getDataDir() string {
+	if dir := os.Getenv("DATA_DIR"); dir != "" {
+		return dir
+	}
+	return "data"
+}
+
+// getCacheTTL retourne la durée de vie des réponses YGOProDeck en cache
+func getCacheTTL() time.Duration {
+	if ttl, err := time.ParseDuration(os.Getenv("CACHE_TTL")); err == nil && ttl > 0 {
+		return ttl
+	}
+	return time.Hour
+}
+
 type Card struct {
-	ID       int         `json:"id"`
-	Name     string      `json:"name"`
-	Type     string      `json:"type"`
-	Desc     string      `json:"desc"`
-	ATK      int         `json:"atk"`
-	DEF      int         `json:"def"`
-	Level    int         `json:"level"`
-	Rarity   []string    `json:"rarity"`
-	Archtype string      `json:"archtype"`
-	Sets     []CardSet   `json:"card_sets"`
-	Images   []CardImage `json:"card_images"`
+	ID            int            `json:"id"`
+	Name          string         `json:"name"`
+	Type          string         `json:"type"`
+	HumanType     string         `json:"humanReadableCardType,omitempty"`
+	FrameType     string         `json:"frameType"`
+	Desc          string         `json:"desc"`
+	ATK           int            `json:"atk"`
+	DEF           int            `json:"def"`
+	Level         int            `json:"level"`
+	Race          string         `json:"race"`
+	Attribute     string         `json:"attribute,omitempty"`
+	Archetype     string         `json:"archetype,omitempty"`
+	Scale         *int           `json:"scale,omitempty"`
+	LinkVal       int            `json:"linkval,omitempty"`
+	LinkMarkers   []string       `json:"linkmarkers,omitempty"`
+	Typeline      []string       `json:"typeline,omitempty"`
+	YGOProDeckURL string         `json:"ygoprodeck_url,omitempty"`
+	Rarity        []string       `json:"rarity"`
+	Sets          []CardSet      `json:"card_sets"`
+	Images        []CardImage    `json:"card_images"`
+	Prices        []CardPrice    `json:"card_prices"`
+	BanlistInfo   *BanlistInfo   `json:"banlist_info,omitempty"`
+	MiscInfo      []CardMiscInfo `json:"misc_info,omitempty"`
 }
 
 type CardSet struct {
@@ -46,22 +106,74 @@ type CardSet struct {
 }
 
 type CardImage struct {
-	ID       int    `json:"id"`
-	ImageURL string `json:"image_url"`
+	ID              int    `json:"id"`
+	ImageURL        string `json:"image_url"`
+	ImageURLSmall   string `json:"image_url_small,omitempty"`
+	ImageURLCropped string `json:"image_url_cropped,omitempty"`
+}
+
+// CardPrice regroupe les prix d'une carte chez chaque revendeur
+type CardPrice struct {
+	Cardmarket   string `json:"cardmarket_price"`
+	TCGPlayer    string `json:"tcgplayer_price"`
+	Ebay         string `json:"ebay_price"`
+	Amazon       string `json:"amazon_price"`
+	CoolStuffInc string `json:"coolstuffinc_price"`
+}
+
+// BanlistInfo donne le statut d'une carte dans chaque format. Un champ vide
+// signifie que la carte n'est pas limitée dans ce format.
+type BanlistInfo struct {
+	TCG  string `json:"ban_tcg,omitempty"`
+	OCG  string `json:"ban_ocg,omitempty"`
+	GOAT string `json:"ban_goat,omitempty"`
+}
+
+// CardMiscInfo contient les informations renvoyées avec misc=yes
+type CardMiscInfo struct {
+	BetaName  string   `json:"beta_name,omitempty"`
+	TreatedAs string   `json:"treated_as,omitempty"`
+	Views     int      `json:"views"`
+	ViewsWeek int      `json:"viewsweek"`
+	Upvotes   int      `json:"upvotes"`
+	Downvotes int      `json:"downvotes"`
+	Formats   []string `json:"formats"`
+	TCGDate   string   `json:"tcg_date,omitempty"`
+	OCGDate   string   `json:"ocg_date,omitempty"`
+	KonamiID  int      `json:"konami_id,omitempty"`
+	HasEffect int      `json:"has_effect"`
+	MDRarity  string   `json:"md_rarity,omitempty"`
 }
 
 type Banlist struct {
+	ID          string       `json:"id,omitempty"` // nom du fichier, non stocké
 	BanlistName string       `json:"banlist_name"`
+	Format      string       `json:"format"`
 	BanlistDate string       `json:"banlist_date"`
 	BanCards    []BannedCard `json:"banned_cards"`
+
+	// Résultat de la vérification des cartes, non stocké : CardsVerified
+	// reste faux tant que l'API ou le miroir n'a pas pu être consulté
+	CardsVerified bool        `json:"cards_verified,omitempty"`
+	CardIssues    []CardIssue `json:"card_issues,omitempty"`
 }
 
-type BannedCard struct {
-	CardName   string `json:"card_name"`
+// CardIssue signale une entrée de banlist dont le code ne correspond pas à
+// la carte indiquée
+type CardIssue struct {
 	CardID     int    `json:"card_id"`
-	BanStatus  string `json:"ban_status"`
-	BanOCGDate string `json:"ban_ocg_date"`
-	BanTCGDate string `json:"ban_tcg_date"`
+	CardName   string `json:"card_name"`
+	Problem    string `json:"problem"`               // not_found ou name_mismatch
+	ActualName string `json:"actual_name,omitempty"` // nom de la carte portant ce code
+}
+
+type BannedCard struct {
+	CardName   string    `json:"card_name"`
+	CardID     int       `json:"card_id"`
+	BanStatus  BanStatus `json:"ban_status"`
+	BanLabel   string    `json:"ban_label,omitempty"`
+	BanOCGDate string    `json:"ban_ocg_date"`
+	BanTCGDate string    `json:"ban_tcg_date"`
 }
 
 type TopDeck struct {
@@ -75,24 +187,116 @@ type TopDeck struct {
 	MainCards    []string `json:"main_cards"`
 	ExtraCards   []string `json:"extra_cards"`
 	SideCards    []string `json:"side_cards"`
+
+	// Renseignés pour les decks enregistrés via /api/decks
+	Owner     string `json:"owner,omitempty"`
+	CreatedAt string `json:"created_at,omitempty"`
+	UpdatedAt string `json:"updated_at,omitempty"`
 }
 
 type APIResponse struct {
-	Data   interface{} `json:"data"`
-	Error  string      `json:"error"`
-	Status string      `json:"status"`
+	Data       interface{} `json:"data"`
+	Error      string      `json:"error"`
+	Status     string      `json:"status"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+
+	// Renseignés quand une recherche par nom ne trouve aucune carte
+	DidYouMean  string           `json:"did_you_mean,omitempty"`
+	Suggestions []nameSuggestion `json:"suggestions,omitempty"`
+}
+
+// cards est la source de données de cartes utilisée par les handlers
+var cards CardClient
+
+// writeJSON écrit le code de statut puis la réponse encodée en JSON
+func writeJSON(w http.ResponseWriter, status int, resp APIResponse) {
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
 }
 
 func main() {
+	cache := newResponseCache(getCacheTTL(), filepath.Join(getDataDir(), "cache"))
+	go cache.run(context.Background())
+	upstream := newYGOClient(getAPIBase(), cache)
+
+	mirror := newCardMirror(upstream, filepath.Join(getDataDir(), "cards.json"))
+	if err := mirror.load(); err != nil {
+		log.Printf("⚠️ Lecture du miroir impossible: %v", err)
+	}
+	var completionsVersion atomic.Int64
+	mirror.onUpdate(func(all []Card) {
+		cardText.Store(buildTextIndex(all))
+		cardNames.Store(buildFuzzyIndex(all))
+
+		// Archétypes présents sur les cartes du miroir tout de suite, puis
+		// liste officielle (celle de /api/archetypes) en arrière-plan pour ne
+		// retarder ni le démarrage ni la synchronisation
+		version := completionsVersion.Add(1)
+		cardCompletions.Store(buildAutocompleteIndex(all, archetypesOf(all)))
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+			defer cancel()
+			archetypes, err := mirror.Archetypes(ctx)
+			if err != nil {
+				return
+			}
+			idx := buildAutocompleteIndex(all, archetypes)
+			// Un chargement plus récent a déjà son propre index
+			if completionsVersion.Load() == version {
+				cardCompletions.Store(idx)
+			}
+		}()
+	})
+	go mirror.run(context.Background(), getMirrorRefresh())
+	cards = mirror
+
+	banlists = newBanlistStore(getBanlistDir(), filepath.Join(getDataDir(), "banlists"))
+	banlistUpstream = upstream
+	if err := banlists.load(); err != nil {
+		log.Fatalf("Erreur chargement banlists: %v", err)
+	}
+	log.Printf("📋 Banlists chargées: %d listes", len(banlists.all()))
+	go banlists.watch(context.Background(), cards)
+
+	decks = newDeckStore(filepath.Join(getDataDir(), "decks.json"))
+	if err := decks.load(); err != nil {
+		log.Fatalf("Erreur chargement des decks: %v", err)
+	}
+	tournaments = newTournamentStore(filepath.Join(getDataDir(), "tournaments.json"))
+	if err := tournaments.load(); err != nil {
+		log.Fatalf("Erreur chargement des tournois: %v", err)
+	}
+
 	mux := http.NewServeMux()
 
 	// Routes API
 	mux.HandleFunc("/api/search-cards", searchCards)
 	mux.HandleFunc("/api/card-info", getCardInfo)
 	mux.HandleFunc("/api/archetypes", getArchetypes)
+	mux.HandleFunc("/api/autocomplete", getAutocomplete)
 	mux.HandleFunc("/api/banlist", getBanlist)
+	mux.HandleFunc("/api/banlist/diff", getBanlistDiff)
+	mux.HandleFunc("/api/banlist/history", getBanlistHistory)
+	mux.HandleFunc("/api/banlist/import", importBanlist)
 	mux.HandleFunc("/api/top-decks", getTopDecks)
 	mux.HandleFunc("/api/decks-by-card", getDecksByCard)
+	mux.HandleFunc("/api/decks", handleDecks)
+	mux.HandleFunc("/api/decks/validate", validateDeckHandler)
+	mux.HandleFunc("/api/decks/stats", getDeckStats)
+	mux.HandleFunc("/api/decks/probability", getHandProbability)
+	mux.HandleFunc("/api/decks/simulate", simulateHands)
+	mux.HandleFunc("/api/decks/compare", getDeckComparison)
+	mux.HandleFunc("/api/decks/archetype", getDeckArchetype)
+	mux.HandleFunc("/api/decks/", handleDeck)
+	mux.HandleFunc("/api/tournaments", handleTournaments)
+	mux.HandleFunc("/api/tournaments/import", importTournaments)
+	mux.HandleFunc("/api/tournaments/", handleTournament)
+	mux.HandleFunc("/api/meta", getMeta)
+	mux.HandleFunc("/api/meta/", getMeta)
+	mux.HandleFunc("/api/ydk/import", importYDK)
+	mux.HandleFunc("/api/ydk/export", exportYDK)
+	mux.HandleFunc("/api/ydke/encode", encodeDeckYDKE)
+	mux.HandleFunc("/api/ydke/parse", parseDeckYDKE)
 
 	// Frontend statique
 	frontendDir := "../frontend"
@@ -137,13 +341,13 @@ func main() {
 
 	port := getPort()
 	log.Printf("🚀 Yu-Gi-Oh! API démarrée sur http://localhost%s", port)
-	log.Printf("📚 API YGOProDeck: %s", ygoprodeckAPIBase)
+	log.Printf("📚 API YGOProDeck: %s", getAPIBase())
 	if err := http.ListenAndServe(port, mux); err != nil {
 		log.Fatalf("Erreur serveur: %v", err)
 	}
 }
 
-// searchCards recherche des cartes via l'API YGOProDeck
+// searchCards recherche des cartes via le client YGOProDeck
 func searchCards(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -155,43 +359,111 @@ func searchCards(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	query := r.URL.Query().Get("q")
-	archtype := r.URL.Query().Get("archtype")
-
-	if query == "" && archtype == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(APIResponse{Error: "Paramètre 'q' ou 'archtype' requis", Status: "error"})
+	q, err := parseCardQuery(r.URL.Query())
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, APIResponse{Error: err.Error(), Status: "error"})
+		return
+	}
+	if q.empty() {
+		writeJSON(w, http.StatusBadRequest, APIResponse{Error: "Au moins un critère de recherche est requis ('q', 'archetype', 'type'...)", Status: "error"})
+		return
+	}
+	page, err := parsePagination(r.URL.Query())
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, APIResponse{Error: err.Error(), Status: "error"})
 		return
 	}
 
-	apiURL := fmt.Sprintf("%s/cardinfo.php", ygoprodeckAPIBase)
-	if query != "" {
-		apiURL += "?fname=" + url.QueryEscape(query)
-	} else if archtype != "" {
-		apiURL += "?archetype=" + url.QueryEscape(archtype)
+	switch mode := r.URL.Query().Get("mode"); mode {
+	case "", "name":
+	case "text":
+		searchCardsByText(w, q, page)
+		return
+	case "fuzzy":
+		searchCardsFuzzy(w, q, page)
+		return
+	default:
+		writeJSON(w, http.StatusBadRequest, APIResponse{Error: "Paramètre 'mode' invalide: " + mode, Status: "error"})
+		return
 	}
 
-	resp, err := http.Get(apiURL)
+	result, err := cards.SearchCards(r.Context(), q)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(APIResponse{Error: err.Error(), Status: "error"})
+		writeJSON(w, http.StatusBadGateway, APIResponse{Error: err.Error(), Status: "error"})
+		return
+	}
+	q.sortCards(result)
+	result = paginate(result, &page)
+
+	resp := APIResponse{Data: result, Status: "success", Pagination: &page}
+	if page.Total == 0 && q.Name != "" {
+		if idx := cardNames.Load(); idx != nil {
+			resp.Suggestions = idx.suggest(q.Name)
+			if len(resp.Suggestions) > 0 {
+				resp.DidYouMean = resp.Suggestions[0].Name
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// searchCardsByText cherche q dans le nom et le texte des cartes via l'index
+// plein texte. Les autres critères filtrent les résultats.
+func searchCardsByText(w http.ResponseWriter, q CardQuery, page Pagination) {
+	idx := cardText.Load()
+	if idx == nil {
+		writeJSON(w, http.StatusServiceUnavailable, APIResponse{Error: "Index plein texte indisponible: le miroir n'est pas encore synchronisé", Status: "error"})
+		return
+	}
+	if q.Name == "" {
+		writeJSON(w, http.StatusBadRequest, APIResponse{Error: "Paramètre 'q' requis en mode texte", Status: "error"})
 		return
 	}
-	defer resp.Body.Close()
 
-	var result struct {
-		Data []Card `json:"data"`
+	text := q.Name
+	q.Name = ""
+	hits := []textHit{}
+	for _, hit := range idx.search(text) {
+		if q.match(hit.Card) {
+			hits = append(hits, hit)
+		}
 	}
+	// Sans clé de tri explicite, les résultats restent triés par pertinence
+	if q.Sort != "" {
+		sort.SliceStable(hits, func(i, j int) bool { return q.less(hits[i].Card, hits[j].Card) })
+	}
+	hits = paginate(hits, &page)
+
+	writeJSON(w, http.StatusOK, APIResponse{Data: hits, Status: "success", Pagination: &page})
+}
 
-	body, _ := io.ReadAll(resp.Body)
-	if err := json.Unmarshal(body, &result); err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(APIResponse{Error: "Erreur parsing JSON", Status: "error"})
+// searchCardsFuzzy cherche les cartes dont le nom ressemble à q malgré les
+// fautes de frappe. Les autres critères filtrent les résultats.
+func searchCardsFuzzy(w http.ResponseWriter, q CardQuery, page Pagination) {
+	idx := cardNames.Load()
+	if idx == nil {
+		writeJSON(w, http.StatusServiceUnavailable, APIResponse{Error: "Recherche approchée indisponible: le miroir n'est pas encore synchronisé", Status: "error"})
+		return
+	}
+	if q.Name == "" {
+		writeJSON(w, http.StatusBadRequest, APIResponse{Error: "Paramètre 'q' requis en mode approché", Status: "error"})
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(APIResponse{Data: result.Data, Status: "success"})
+	text := q.Name
+	q.Name = ""
+	matches := []fuzzyMatch{}
+	for _, m := range idx.search(text, 0) {
+		if q.match(m.Card) {
+			matches = append(matches, m)
+		}
+	}
+	if q.Sort != "" {
+		sort.SliceStable(matches, func(i, j int) bool { return q.less(matches[i].Card, matches[j].Card) })
+	}
+	matches = paginate(matches, &page)
+
+	writeJSON(w, http.StatusOK, APIResponse{Data: matches, Status: "success", Pagination: &page})
 }
 
 // getCardInfo récupère les infos d'une carte spécifique
@@ -200,39 +472,26 @@ func getCardInfo(w http.ResponseWriter, r *http.Request) {
 
 	cardID := r.URL.Query().Get("id")
 	if cardID == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(APIResponse{Error: "Paramètre 'id' requis", Status: "error"})
+		writeJSON(w, http.StatusBadRequest, APIResponse{Error: "Paramètre 'id' requis", Status: "error"})
 		return
 	}
-
-	apiURL := fmt.Sprintf("%s/cardinfo.php?id=%s", ygoprodeckAPIBase, cardID)
-	resp, err := http.Get(apiURL)
+	id, err := strconv.Atoi(cardID)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(APIResponse{Error: err.Error(), Status: "error"})
+		writeJSON(w, http.StatusBadRequest, APIResponse{Error: "Paramètre 'id' invalide", Status: "error"})
 		return
 	}
-	defer resp.Body.Close()
-
-	body, _ := io.ReadAll(resp.Body)
-	var result struct {
-		Data []Card `json:"data"`
-	}
 
-	if err := json.Unmarshal(body, &result); err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(APIResponse{Error: "Erreur parsing JSON", Status: "error"})
+	card, err := cards.CardByID(r.Context(), id)
+	if errors.Is(err, errCardNotFound) {
+		writeJSON(w, http.StatusNotFound, APIResponse{Error: "Carte non trouvée", Status: "error"})
 		return
 	}
-
-	if len(result.Data) == 0 {
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(APIResponse{Error: "Carte non trouvée", Status: "error"})
+	if err != nil {
+		writeJSON(w, http.StatusBadGateway, APIResponse{Error: err.Error(), Status: "error"})
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(APIResponse{Data: result.Data[0], Status: "success"})
+	writeJSON(w, http.StatusOK, APIResponse{Data: card, Status: "success"})
 }
 
 // getArchetypes récupère la liste de tous les archétypes
@@ -240,91 +499,58 @@ func getArchetypes(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 
-	apiURL := fmt.Sprintf("%s/archetypes.php", ygoprodeckAPIBase)
-	resp, err := http.Get(apiURL)
+	archetypes, err := cards.Archetypes(r.Context())
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(APIResponse{Error: err.Error(), Status: "error"})
-		return
-	}
-	defer resp.Body.Close()
-
-	body, _ := io.ReadAll(resp.Body)
-	var archetypes []string
-	if err := json.Unmarshal(body, &archetypes); err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(APIResponse{Error: "Erreur parsing JSON", Status: "error"})
+		writeJSON(w, http.StatusBadGateway, APIResponse{Error: err.Error(), Status: "error"})
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(APIResponse{Data: archetypes, Status: "success"})
+	writeJSON(w, http.StatusOK, APIResponse{Data: archetypes, Status: "success"})
 }
 
-// getBanlist retourne les banlists réelles 2025-2026
+// getBanlist retourne les banlists, éventuellement filtrées par format. Avec
+// une date, seule la liste en vigueur ce jour-là dans ce format est renvoyée.
 func getBanlist(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 
-	banlists := []Banlist{
-		{
-			BanlistName: "🇬🇧 TCG - January 15, 2026",
-			BanlistDate: "2026-01-15",
-			BanCards: []BannedCard{
-				{CardName: "Tearlament Scheiren", CardID: 100371067, BanStatus: "❌ BANNED", BanTCGDate: "2026-01-15"},
-				{CardName: "Tearlament Rulkallos", CardID: 100371068, BanStatus: "⚠️ LIMITED 1", BanTCGDate: "2026-01-15"},
-				{CardName: "Tearlament Scream", CardID: 100371066, BanStatus: "⚠️ LIMITED 2", BanTCGDate: "2026-01-15"},
-				{CardName: "Kashtira Argodem", CardID: 100369999, BanStatus: "⚠️ LIMITED 1", BanTCGDate: "2026-01-15"},
-				{CardName: "Kashtira Birthright", CardID: 100370001, BanStatus: "⚠️ LIMITED 1", BanTCGDate: "2026-01-15"},
-				{CardName: "Swordsoul Strategist Longyuan", CardID: 100389999, BanStatus: "⚠️ LIMITED 1", BanTCGDate: "2026-01-15"},
-				{CardName: "Adventurer's Sword", CardID: 100370000, BanStatus: "⚠️ LIMITED 2", BanTCGDate: "2026-01-15"},
-				{CardName: "Triple Tactics Talent", CardID: 11655299, BanStatus: "⚠️ LIMITED 2", BanTCGDate: "2026-01-15"},
-			},
-		},
-		{
-			BanlistName: "🇯🇵 OCG - January 1, 2026",
-			BanlistDate: "2026-01-01",
-			BanCards: []BannedCard{
-				{CardName: "Tearlament Scheiren", CardID: 100371067, BanStatus: "❌ BANNED", BanOCGDate: "2026-01-01"},
-				{CardName: "Tearlament Rulkallos", CardID: 100371068, BanStatus: "⚠️ LIMITED 1", BanOCGDate: "2026-01-01"},
-				{CardName: "Kashtira Argodem", CardID: 100369999, BanStatus: "⚠️ LIMITED 1", BanOCGDate: "2026-01-01"},
-				{CardName: "Swordsoul Strategist Longyuan", CardID: 100389999, BanStatus: "⚠️ LIMITED 1", BanOCGDate: "2026-01-01"},
-			},
-		},
-		{
-			BanlistName: "🇬🇧 TCG - September 15, 2025",
-			BanlistDate: "2025-09-15",
-			BanCards: []BannedCard{
-				{CardName: "Tearlament Rulkallos", CardID: 100371068, BanStatus: "❌ BANNED", BanTCGDate: "2025-09-15"},
-				{CardName: "Tearlament Scheiren", CardID: 100371067, BanStatus: "⚠️ LIMITED 1", BanTCGDate: "2025-09-15"},
-				{CardName: "Kashtira Argodem", CardID: 100369999, BanStatus: "⚠️ LIMITED 1", BanTCGDate: "2025-09-15"},
-				{CardName: "Swordsoul Strategist Longyuan", CardID: 100389999, BanStatus: "⚠️ LIMITED 2", BanTCGDate: "2025-09-15"},
-				{CardName: "Adventurer's Sword", CardID: 100370000, BanStatus: "⚠️ LIMITED 2", BanTCGDate: "2025-09-15"},
-			},
-		},
-		{
-			BanlistName: "🇯🇵 OCG - September 1, 2025",
-			BanlistDate: "2025-09-01",
-			BanCards: []BannedCard{
-				{CardName: "Tearlament Rulkallos", CardID: 100371068, BanStatus: "❌ BANNED", BanOCGDate: "2025-09-01"},
-				{CardName: "Tearlament Scheiren", CardID: 100371067, BanStatus: "⚠️ LIMITED 1", BanOCGDate: "2025-09-01"},
-				{CardName: "Kashtira Argodem", CardID: 100369999, BanStatus: "⚠️ LIMITED 1", BanOCGDate: "2025-09-01"},
-			},
-		},
-		{
-			BanlistName: "🇬🇧 TCG - April 14, 2025",
-			BanlistDate: "2025-04-14",
-			BanCards: []BannedCard{
-				{CardName: "Tearlament Scheiren", CardID: 100371067, BanStatus: "❌ BANNED", BanTCGDate: "2025-04-14"},
-				{CardName: "Kashtira Argodem", CardID: 100369999, BanStatus: "⚠️ LIMITED 1", BanTCGDate: "2025-04-14"},
-				{CardName: "Tearlament Scream", CardID: 100371066, BanStatus: "⚠️ LIMITED 1", BanTCGDate: "2025-04-14"},
-				{CardName: "Swordsoul Strategist Longyuan", CardID: 100389999, BanStatus: "⚠️ LIMITED 2", BanTCGDate: "2025-04-14"},
-			},
-		},
+	format := r.URL.Query().Get("format")
+	date := r.URL.Query().Get("date")
+	lang, err := parseLang(r.URL.Query().Get("lang"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, APIResponse{Error: err.Error(), Status: "error"})
+		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(APIResponse{Data: banlists, Status: "success"})
+	if format == "" {
+		if date != "" {
+			writeJSON(w, http.StatusBadRequest, APIResponse{Error: "Paramètre 'format' requis avec 'date'", Status: "error"})
+			return
+		}
+		writeJSON(w, http.StatusOK, APIResponse{Data: labelBanlists(banlists.all(), lang), Status: "success"})
+		return
+	}
+
+	canonical, ok := normalizeFormat(format)
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, APIResponse{Error: "Format inconnu: " + format, Status: "error"})
+		return
+	}
+	if date == "" {
+		writeJSON(w, http.StatusOK, APIResponse{Data: labelBanlists(banlists.byFormat(canonical), lang), Status: "success"})
+		return
+	}
+
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		writeJSON(w, http.StatusBadRequest, APIResponse{Error: "Paramètre 'date' invalide (AAAA-MM-JJ): " + date, Status: "error"})
+		return
+	}
+	banlist, ok := banlists.effective(canonical, date)
+	if !ok {
+		writeJSON(w, http.StatusNotFound, APIResponse{Error: fmt.Sprintf("Aucune banlist %s en vigueur le %s", canonical, date), Status: "error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, APIResponse{Data: banlist.withLabels(lang), Status: "success"})
 }
 
 // getTopDecks retourne les meilleurs decks 2025-2026 avec vraies données
@@ -332,137 +558,7 @@ func getTopDecks(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 
-	topDecks := []TopDeck{
-		{
-			ID:           "ycs_miami_2026",
-			DeckName:     "🏆 Swordsoul Strategist - YCS Miami 2026",
-			DeckArchtype: "Swordsoul",
-			Tournament:   "YCS Miami 2026",
-			Date:         "2026-01-18",
-			Placement:    "1st Place",
-			Player:       "Champion Player",
-			MainCards: []string{
-				"Swordsoul Strategist Longyuan", "Swordsoul Strategist Longyuan", "Swordsoul Strategist Longyuan",
-				"Swordsoul Grandmaster Chixiao", "Swordsoul Grandmaster Chixiao", "Swordsoul Grandmaster Chixiao",
-				"Swordsoul Grandmaster Chixiao", "Swordsoul Grandmaster Chixiao", "Swordsoul Strategist Harumichiya",
-				"Swordsoul Strategist Harumichiya", "Swordsoul Strategist Harumichiya", "Rite of Taros",
-				"Rite of Taros", "Rite of Taros", "Taros", "Taros",
-				"Triple Tactics Talent", "Triple Tactics Talent", "Ash Blossom & Joyous Spring",
-				"Ash Blossom & Joyous Spring", "Crossout Designator", "Crossout Designator", "Crossout Designator",
-				"Solemn Judgment", "Solemn Judgment", "Solemn Warning", "Infinite Impermanence", "Infinite Impermanence",
-				"Infinite Impermanence", "Shifter Shearable", "Shifter Shearable", "Shifter Shearable",
-				"Forbidden Droplet", "Forbidden Droplet", "Forbidden Droplet", "Harpie's Feather Duster",
-				"Mystical Space Typhoon", "Mystical Space Typhoon", "Mystical Space Typhoon", "Called by the Grave",
-				"Called by the Grave", "Called by the Grave", "Nibiru, the Primal Being",
-				"Nibiru, the Primal Being", "Nibiru, the Primal Being", "Ghost Ogre & Snow Rabbit",
-			},
-			ExtraCards: []string{
-				"Swordsoul Supremacy Sovereign Chixiao", "Swordsoul Grandmaster Chixiao",
-				"Swordsoul Strategist Longyuan", "Mirrorjade the Iceblade Dragon",
-				"Accesscode Talker", "Downerd Magician",
-				"Underworld Goddess of the Closed World", "Wee Witch's Apprentice",
-				"Swordsoul Mipham, the Vital Spirit", "Bystial Revolving Suite",
-				"Phantom Knights of Break Sword", "Swordsoul Strategist Longyuan",
-				"Decode Talker", "Pentestag", "Crystron Halqifibrax",
-			},
-			SideCards: []string{
-				"Shifter Shearable", "Shifter Shearable",
-				"Mystical Space Typhoon", "Mystical Space Typhoon",
-				"Shifter Shearable", "Dogmatika Ecclesia, the Virtuous",
-				"Dogmatika Ecclesia, the Virtuous", "Dogmatika Ecclesia, the Virtuous",
-				"Lancea of the Darkwood", "Lancea of the Darkwood",
-				"Lancea of the Darkwood", "Red Reboot",
-				"Red Reboot", "Effect Veiler", "Effect Veiler",
-			},
-		},
-		{
-			ID:           "asian_champ_2026",
-			DeckName:     "🥈 Tearlaments - Asian Championship 2026",
-			DeckArchtype: "Tearlament",
-			Tournament:   "Asian Championship 2026",
-			Date:         "2026-01-25",
-			Placement:    "1st Place",
-			Player:       "Top Player",
-			MainCards: []string{
-				"Tearlament Scheiren", "Tearlament Rulkallos", "Tearlament Rulkallos",
-				"Tearlament Scream", "Tearlament Scream", "Tearlament Sulliek",
-				"Tearlament Sulliek", "Tearlament Pearlescence", "Tearlament Pearlescence",
-				"Tearlament Pearlescence", "Kashtira Argodem", "Kashtira Argodem",
-				"Kashtira Unicorn", "Kashtira Unicorn", "Kashtira Unicorn",
-				"Tearlaments Kashtira Scheiren", "Tearlaments Kashtira Scheiren", "Tearlaments Kashtira Scheiren",
-				"Ash Blossom & Joyous Spring", "Ash Blossom & Joyous Spring", "Ghost Ogre & Snow Rabbit",
-				"Effect Veiler", "Crossout Designator", "Crossout Designator",
-				"Triple Tactics Talent", "Triple Tactics Talent", "Forbidden Droplet",
-				"Forbidden Droplet", "Solemn Judgment", "Solemn Warning",
-				"Infinite Impermanence", "Infinite Impermanence", "Tearlament Kitkaliath",
-				"Tearlament Kitkaliath", "Shifter Shearable", "Tearlament Kitkaliah",
-				"Tearlament Kitkaliah", "Tearlament Kitkaliah", "Nibiru, the Primal Being",
-				"Nibiru, the Primal Being", "Nibiru, the Primal Being",
-			},
-			ExtraCards: []string{
-				"Tearlament Scheiren", "Tearlament Rulkallos",
-				"Tearlament Pearlescence", "Tearlaments Kashtira Scheiren",
-				"Accesscode Talker", "Decode Talker",
-				"Underworld Goddess of the Closed World", "Bystial Dolmkite",
-				"Bystial Saronir", "Herald of Arc Light",
-				"Crystron Halqifibrax", "Schism, The Omen Dragon",
-				"Phantom Knights of Break Sword", "Spider Silk",
-				"Wee Witch's Apprentice",
-			},
-			SideCards: []string{
-				"Shifter Shearable", "Shifter Shearable",
-				"Dogmatika Ecclesia, the Virtuous", "Dogmatika Ecclesia, the Virtuous",
-				"Dogmatika Ecclesia, the Virtuous", "Lancea of the Darkwood",
-				"Lancea of the Darkwood", "Lancea of the Darkwood",
-				"Effect Veiler", "Effect Veiler",
-				"Red Reboot", "Red Reboot",
-				"Mystical Space Typhoon", "Harpie's Feather Duster",
-				"Called by the Grave",
-			},
-		},
-		{
-			ID:           "regional_2026_01",
-			DeckName:     "🥉 Snake-Eye - European Regional 2026",
-			DeckArchtype: "Snake-Eye",
-			Tournament:   "European Regional 2026",
-			Date:         "2026-01-20",
-			Placement:    "1st Place",
-			Player:       "European Champion",
-			MainCards: []string{
-				"Snake-Eye Aquamirror", "Snake-Eye Aquamirror", "Snake-Eye Aquamirror",
-				"Snake-Eye Fang", "Snake-Eye Fang", "Snake-Eye Fang",
-				"Snake-Eye GodEye", "Snake-Eye GodEye", "Snake-Eye GodEye",
-				"Snake-Eye Titanswallow", "Snake-Eye Titanswallow", "Snake-Eye Titanswallow",
-				"Branded Beast", "Branded Beast", "Branded Beast",
-				"Ash Blossom & Joyous Spring", "Ash Blossom & Joyous Spring", "Ash Blossom & Joyous Spring",
-				"Crossout Designator", "Crossout Designator", "Crossout Designator",
-				"Triple Tactics Talent", "Triple Tactics Talent", "Forbidden Droplet",
-				"Forbidden Droplet", "Forbidden Droplet", "Solemn Judgment",
-				"Solemn Judgment", "Solemn Warning", "Infinite Impermanence",
-				"Infinite Impermanence", "Infinite Impermanence", "Nibiru, the Primal Being",
-				"Nibiru, the Primal Being", "Nibiru, the Primal Being", "Effect Veiler",
-				"Effect Veiler", "Spright Blue", "Spright Blue", "Spright Blue",
-			},
-			ExtraCards: []string{
-				"Mirrorjade the Iceblade Dragon", "Predaplant Verte Anaconda",
-				"Accesscode Talker", "Decode Talker",
-				"Underworld Goddess of the Closed World", "Bystial Dolmkite",
-				"Bystial Saronir", "Herald of Arc Light",
-				"Phantom Knights of Break Sword", "Spider Silk",
-				"Wee Witch's Apprentice", "Schism, The Omen Dragon",
-				"Crystron Halqifibrax", "Downerd Magician", "Pentestag",
-			},
-			SideCards: []string{
-				"Mystical Space Typhoon", "Mystical Space Typhoon",
-				"Harpie's Feather Duster", "Called by the Grave",
-				"Called by the Grave", "Dogmatika Ecclesia, the Virtuous",
-				"Dogmatika Ecclesia, the Virtuous", "Lancea of the Darkwood",
-				"Lancea of the Darkwood", "Red Reboot",
-				"Red Reboot", "Shifter Shearable",
-				"Shifter Shearable", "Effect Veiler", "Effect Veiler",
-			},
-		},
-	}
+	topDecks := getAllTopDecks()
 
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(APIResponse{Data: topDecks, Status: "success"})
@@ -521,8 +617,14 @@ func getDecksByCard(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(APIResponse{Data: matchingDecks, Status: "success"})
 }
 
-// getAllTopDecks retourne tous les decks
+// getAllTopDecks retourne les top decks historiques suivis des decks liés aux
+// classements des tournois enregistrés
 func getAllTopDecks() []TopDeck {
+	return append(builtinTopDecks(), tournamentTopDecks()...)
+}
+
+// builtinTopDecks retourne les top decks fournis avec l'application
+func builtinTopDecks() []TopDeck {
 	return []TopDeck{
 		{
 			ID:           "ycs_miami_2026",
@@ -585,8 +687,8 @@ func getAllTopDecks() []TopDeck {
 				"Effect Veiler", "Crossout Designator", "Crossout Designator",
 				"Triple Tactics Talent", "Triple Tactics Talent", "Forbidden Droplet",
 				"Forbidden Droplet", "Solemn Judgment", "Solemn Warning",
-				"Infinite Impermanence", "Infinite Impermanence", "Tearlament Kitkaliah",
-				"Tearlament Kitkaliah", "Shifter Shearable", "Tearlament Kitkaliah",
+				"Infinite Impermanence", "Infinite Impermanence", "Tearlament Kitkaliath",
+				"Tearlament Kitkaliath", "Shifter Shearable", "Tearlament Kitkaliah",
 				"Tearlament Kitkaliah", "Tearlament Kitkaliah", "Nibiru, the Primal Being",
 				"Nibiru, the Primal Being", "Nibiru, the Primal Being",
 			},
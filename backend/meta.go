@@ -0,0 +1,243 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// defaultMetaDays est la fenêtre par défaut des rapports : une semaine
+// jusqu'à aujourd'hui inclus
+const defaultMetaDays = 7
+
+// tierWeights pondère les points selon le niveau du tournoi
+var tierWeights = map[int]float64{
+	tierPremier:  3,
+	tierRegional: 2,
+	tierLocal:    1,
+}
+
+// MetaWindow décrit la période et le format d'un rapport
+type MetaWindow struct {
+	Format      string `json:"format"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Tournaments int    `json:"tournaments"`
+	Entries     int    `json:"entries"`             // classements d'archétype connu
+	Unknown     int    `json:"unknown_entries"`     // classements sans archétype, ignorés
+	Builtin     int    `json:"builtin_tournaments"` // tournois issus des top decks livrés
+}
+
+// ArchetypeMeta regroupe les indicateurs d'un archétype sur la période
+type ArchetypeMeta struct {
+	Archetype  string  `json:"archetype"`
+	Entries    int     `json:"entries"`
+	Share      float64 `json:"share"`    // part des classements
+	Eligible   int     `json:"eligible"` // classements de tournois au top cut connu
+	TopCuts    int     `json:"top_cuts"`
+	Conversion float64 `json:"conversion"` // top_cuts / eligible
+	Wins       int     `json:"wins"`
+	Points     float64 `json:"points"`
+}
+
+// MetaReport est un rapport de métagame
+type MetaReport struct {
+	Window     MetaWindow      `json:"window"`
+	Archetypes []ArchetypeMeta `json:"archetypes"`
+}
+
+// placementPoints attribue des points à une place : le niveau du tournoi,
+// multiplié par log2 du nombre de joueurs, divisé selon la place (1 pour le
+// vainqueur, 1/2 pour le finaliste, 1/3 pour le top 4, 1/4 pour le top 8...)
+func placementPoints(t Tournament, placement int) float64 {
+	size := math.Max(1, math.Log2(float64(t.Players)))
+	return tierWeights[t.Tier] * size / (1 + math.Log2(float64(placement)))
+}
+
+// computeMeta calcule les indicateurs de chaque archétype sur les tournois
+// donnés. Avec un top cut connu, seules les places qualifiées rapportent des
+// points.
+func computeMeta(window MetaWindow, list []Tournament) MetaReport {
+	rows := make(map[string]*ArchetypeMeta)
+	names := make(map[string]string)
+	for _, t := range list {
+		window.Tournaments++
+		for _, st := range t.Standings {
+			archetype := strings.TrimSpace(st.Archetype)
+			if archetype == "" {
+				window.Unknown++
+				continue
+			}
+			key := strings.ToLower(archetype)
+			if _, ok := names[key]; !ok {
+				names[key] = archetype
+				rows[key] = &ArchetypeMeta{Archetype: archetype}
+			}
+			row := rows[key]
+			window.Entries++
+			row.Entries++
+			if st.Placement == 1 {
+				row.Wins++
+			}
+			if t.TopCut > 0 {
+				row.Eligible++
+				if st.Placement <= t.TopCut {
+					row.TopCuts++
+				}
+			}
+			if t.TopCut == 0 || st.Placement <= t.TopCut {
+				row.Points += placementPoints(t, st.Placement)
+			}
+		}
+	}
+
+	report := MetaReport{Window: window, Archetypes: []ArchetypeMeta{}}
+	for _, row := range rows {
+		row.Share = roundScore(float64(row.Entries) / float64(window.Entries))
+		if row.Eligible > 0 {
+			row.Conversion = roundScore(float64(row.TopCuts) / float64(row.Eligible))
+		}
+		row.Points = math.Round(row.Points*100) / 100
+		report.Archetypes = append(report.Archetypes, *row)
+	}
+	sortMeta(report.Archetypes, func(a ArchetypeMeta) float64 { return a.Points })
+	return report
+}
+
+// builtinTournaments regroupe les top decks livrés avec l'application par
+// tournoi pour les compter comme des tournois enregistrés. Ces listes ne
+// précisent ni le format (elles suivent la banlist TCG), ni le nombre de
+// joueurs, ni le top cut ; le niveau est déduit du nom du tournoi.
+func builtinTournaments() []Tournament {
+	var list []Tournament
+	index := make(map[string]int)
+	for _, d := range builtinTopDecks() {
+		var placement int
+		if _, err := fmt.Sscanf(d.Placement, "%d", &placement); err != nil || placement < 1 {
+			continue
+		}
+		i, ok := index[d.Tournament]
+		if !ok {
+			i = len(list)
+			index[d.Tournament] = i
+			list = append(list, Tournament{
+				ID:     "builtin-" + d.ID,
+				Name:   d.Tournament,
+				Date:   d.Date,
+				Format: "TCG",
+				Tier:   tierFromName(d.Tournament),
+			})
+		}
+		list[i].Standings = append(list[i].Standings, Standing{Placement: placement, Player: d.Player, Archetype: d.DeckArchtype})
+	}
+	return list
+}
+
+// tierFromName devine le niveau d'un tournoi d'après son nom
+func tierFromName(name string) int {
+	lower := strings.ToLower(name)
+	for _, word := range []string{"ycs", "wcq", "championship", "world"} {
+		if strings.Contains(lower, word) {
+			return tierPremier
+		}
+	}
+	if strings.Contains(lower, "regional") {
+		return tierRegional
+	}
+	return tierLocal
+}
+
+// sortMeta trie les archétypes par indicateur décroissant puis par nom
+func sortMeta(rows []ArchetypeMeta, metric func(ArchetypeMeta) float64) {
+	sort.Slice(rows, func(i, j int) bool {
+		if mi, mj := metric(rows[i]), metric(rows[j]); mi != mj {
+			return mi > mj
+		}
+		return rows[i].Archetype < rows[j].Archetype
+	})
+}
+
+// parseMetaWindow lit format (TCG par défaut), from et to. Sans dates, la
+// fenêtre couvre les defaultMetaDays derniers jours.
+func parseMetaWindow(r *http.Request) (MetaWindow, error) {
+	query := r.URL.Query()
+	window := MetaWindow{Format: "TCG", From: query.Get("from"), To: query.Get("to")}
+	if f := query.Get("format"); f != "" {
+		format, ok := normalizeFormat(f)
+		if !ok {
+			return window, fmt.Errorf("Format inconnu: %s", f)
+		}
+		window.Format = format
+	}
+	if window.To == "" {
+		window.To = time.Now().Format("2006-01-02")
+	}
+	to, err := time.Parse("2006-01-02", window.To)
+	if err != nil {
+		return window, fmt.Errorf("Paramètre 'to' invalide (AAAA-MM-JJ): %s", window.To)
+	}
+	if window.From == "" {
+		window.From = to.AddDate(0, 0, 1-defaultMetaDays).Format("2006-01-02")
+	}
+	if _, err := time.Parse("2006-01-02", window.From); err != nil {
+		return window, fmt.Errorf("Paramètre 'from' invalide (AAAA-MM-JJ): %s", window.From)
+	}
+	if window.From > window.To {
+		return window, fmt.Errorf("La date 'from' doit précéder 'to'")
+	}
+	return window, nil
+}
+
+// getMeta calcule un rapport de métagame sur une période et un format :
+// /api/meta (rapport complet, classé par points), /api/meta/share,
+// /api/meta/conversion et /api/meta/points (classés par cet indicateur)
+func getMeta(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	var metric func(ArchetypeMeta) float64
+	switch strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/meta"), "/") {
+	case "", "/snapshot", "/points":
+	case "/share":
+		metric = func(a ArchetypeMeta) float64 { return a.Share }
+	case "/conversion":
+		metric = func(a ArchetypeMeta) float64 { return a.Conversion }
+	default:
+		writeJSON(w, http.StatusNotFound, APIResponse{Error: "Rapport inconnu: " + r.URL.Path, Status: "error"})
+		return
+	}
+
+	window, err := parseMetaWindow(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, APIResponse{Error: err.Error(), Status: "error"})
+		return
+	}
+	// Les top decks livrés comptent comme des tournois, sauf si le même
+	// tournoi a été enregistré
+	var list []Tournament
+	stored := make(map[string]bool)
+	inWindow := func(t Tournament) bool {
+		return t.Format == window.Format && t.Date >= window.From && t.Date <= window.To
+	}
+	for _, t := range tournaments.list() {
+		stored[strings.ToLower(t.Name)] = true
+		if inWindow(t) {
+			list = append(list, t)
+		}
+	}
+	for _, t := range builtinTournaments() {
+		if !stored[strings.ToLower(t.Name)] && inWindow(t) {
+			list = append(list, t)
+			window.Builtin++
+		}
+	}
+
+	report := computeMeta(window, list)
+	if metric != nil {
+		sortMeta(report.Archetypes, metric)
+	}
+	writeJSON(w, http.StatusOK, APIResponse{Data: report, Status: "success"})
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPlacementPoints(t *testing.T) {
+	ycs := Tournament{Tier: tierPremier, Players: 256}
+	tests := []struct {
+		name      string
+		t         Tournament
+		placement int
+		want      float64
+	}{
+		{"vainqueur", ycs, 1, 24},
+		{"finaliste", ycs, 2, 12},
+		{"top 4", ycs, 4, 8},
+		{"nombre de joueurs inconnu", Tournament{Tier: tierPremier}, 1, 3},
+		{"tournoi local", Tournament{Tier: tierLocal, Players: 16}, 8, 1},
+	}
+	for _, tt := range tests {
+		if got := placementPoints(tt.t, tt.placement); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: placementPoints() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestComputeMeta(t *testing.T) {
+	list := []Tournament{
+		{Tier: tierPremier, Players: 256, TopCut: 8, Standings: []Standing{
+			{Placement: 1, Archetype: "Swordsoul"},
+			{Placement: 2, Archetype: "Tearlament"},
+			{Placement: 16, Archetype: "Swordsoul"},
+			{Placement: 3},
+		}},
+		{Tier: tierLocal, Players: 16, Standings: []Standing{
+			{Placement: 1, Archetype: " tearlament "},
+		}},
+	}
+	got := computeMeta(MetaWindow{Format: "TCG"}, list)
+
+	wantWindow := MetaWindow{Format: "TCG", Tournaments: 2, Entries: 4, Unknown: 1}
+	if got.Window != wantWindow {
+		t.Errorf("Window = %+v, want %+v", got.Window, wantWindow)
+	}
+	want := []ArchetypeMeta{
+		// Le 16e est hors du top cut : classement compté, sans points
+		{Archetype: "Swordsoul", Entries: 2, Share: 0.5, Eligible: 2, TopCuts: 1, Conversion: 0.5, Wins: 1, Points: 24},
+		// Même archétype malgré la casse ; top cut inconnu pour le local
+		{Archetype: "Tearlament", Entries: 2, Share: 0.5, Eligible: 1, TopCuts: 1, Conversion: 1, Wins: 1, Points: 16},
+	}
+	if !reflect.DeepEqual(got.Archetypes, want) {
+		t.Errorf("Archetypes = %+v, want %+v", got.Archetypes, want)
+	}
+
+	empty := computeMeta(MetaWindow{Format: "TCG"}, nil)
+	if empty.Archetypes == nil || len(empty.Archetypes) != 0 {
+		t.Errorf("rapport vide = %+v", empty.Archetypes)
+	}
+}
+
+func TestParseMetaWindow(t *testing.T) {
+	tests := []struct {
+		query    string
+		from, to string
+		format   string
+		wantErr  bool
+	}{
+		{query: "to=2026-01-31", from: "2026-01-25", to: "2026-01-31", format: "TCG"},
+		{query: "format=ocg&from=2026-01-01&to=2026-01-31", from: "2026-01-01", to: "2026-01-31", format: "OCG"},
+		{query: "format=inconnu", wantErr: true},
+		{query: "to=31/01/2026", wantErr: true},
+		{query: "from=2026-02-01&to=2026-01-31", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w, err := parseMetaWindow(httptest.NewRequest(http.MethodGet, "/api/meta?"+tt.query, nil))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("erreur = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (w.From != tt.from || w.To != tt.to || w.Format != tt.format) {
+				t.Errorf("fenêtre = %+v, want %s..%s %s", w, tt.from, tt.to, tt.format)
+			}
+		})
+	}
+}
+
+func TestTierFromName(t *testing.T) {
+	tests := map[string]int{
+		"YCS Miami 2026":          tierPremier,
+		"Asian Championship 2026": tierPremier,
+		"European Regional 2026":  tierRegional,
+		"Locals du jeudi":         tierLocal,
+	}
+	for name, want := range tests {
+		if got := tierFromName(name); got != want {
+			t.Errorf("tierFromName(%q) = %d, want %d", name, got, want)
+		}
+	}
+}
+
+// Sans tournoi enregistré, le rapport repose sur les top decks livrés
+func TestGetMetaBuiltin(t *testing.T) {
+	previous := tournaments
+	tournaments = newTournamentStore(filepath.Join(t.TempDir(), "tournaments.json"))
+	t.Cleanup(func() { tournaments = previous })
+
+	meta := func() MetaReport {
+		rec := httptest.NewRecorder()
+		getMeta(rec, httptest.NewRequest(http.MethodGet, "/api/meta?from=2025-01-01&to=2026-12-31", nil))
+		var resp struct{ Data MetaReport }
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp.Data
+	}
+
+	builtin := builtinTournaments()
+	report := meta()
+	if report.Window.Builtin != len(builtin) || report.Window.Tournaments != len(builtin) || len(report.Archetypes) == 0 {
+		t.Fatalf("fenêtre = %+v, %d archétypes", report.Window, len(report.Archetypes))
+	}
+
+	// Un tournoi enregistré sous le même nom remplace la version livrée
+	same := builtin[0]
+	same.ID, same.Players = "", 512
+	if _, _, err := tournaments.put("ana", []Tournament{same}); err != nil {
+		t.Fatal(err)
+	}
+	report = meta()
+	if report.Window.Builtin != len(builtin)-1 || report.Window.Tournaments != len(builtin) {
+		t.Errorf("après enregistrement, fenêtre = %+v", report.Window)
+	}
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// mirrorFormat est incrémenté à chaque évolution du modèle Card afin que les
+// miroirs enregistrés avec l'ancien modèle soient retéléchargés.
+const mirrorFormat = 2
+
+// syncTimeout borne la durée d'une synchronisation complète
+const syncTimeout = 10 * time.Minute
+
+// cardMirror garde une copie locale de toute la base YGOProDeck et répond aux
+// recherches sans réseau une fois la première synchronisation faite. Tant que
+// le miroir est vide, les requêtes sont transmises au client amont.
+type cardMirror struct {
+	upstream *ygoClient
+	path     string
+
+	syncMu sync.Mutex
+
+	mu       sync.RWMutex
+	snapshot mirrorSnapshot
+	byID     map[int]int
+	byName   map[string]int // noms en minuscules
+
+	listeners []func([]Card)
+}
+
+// mirrorSnapshot est la forme enregistrée sur disque du miroir
+type mirrorSnapshot struct {
+	Format     int       `json:"format"`
+	Version    string    `json:"database_version"`
+	LastUpdate string    `json:"last_update"`
+	SyncedAt   time.Time `json:"synced_at"`
+	Cards      []Card    `json:"cards"`
+}
+
+func newCardMirror(upstream *ygoClient, path string) *cardMirror {
+	return &cardMirror{upstream: upstream, path: path}
+}
+
+// onUpdate enregistre une fonction appelée à chaque chargement de cartes
+func (m *cardMirror) onUpdate(fn func([]Card)) {
+	m.mu.Lock()
+	m.listeners = append(m.listeners, fn)
+	cards := m.snapshot.Cards
+	m.mu.Unlock()
+
+	if len(cards) > 0 {
+		fn(cards)
+	}
+}
+
+// load lit le miroir enregistré sur disque, s'il existe
+func (m *cardMirror) load() error {
+	var snap mirrorSnapshot
+	if err := readJSONFile(m.path, &snap); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if snap.Format != mirrorFormat {
+		log.Printf("♻️ Miroir au format %d ignoré, resynchronisation nécessaire", snap.Format)
+		return nil
+	}
+	m.replace(snap)
+	log.Printf("💾 Miroir chargé: %d cartes (version %s)", len(snap.Cards), snap.Version)
+	return nil
+}
+
+// sync retélécharge la base uniquement si sa version amont a changé
+func (m *cardMirror) sync(ctx context.Context) error {
+	m.syncMu.Lock()
+	defer m.syncMu.Unlock()
+
+	version, err := m.upstream.DBVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	m.mu.RLock()
+	current := m.snapshot
+	m.mu.RUnlock()
+	if current.Version == version.Version && len(current.Cards) > 0 {
+		return nil
+	}
+
+	log.Printf("🔄 Synchronisation du miroir (version %s → %s)", current.Version, version.Version)
+	cards, err := m.upstream.AllCards(ctx)
+	if err != nil {
+		return err
+	}
+	if len(cards) == 0 {
+		return errors.New("cardinfo.php: aucune carte reçue")
+	}
+
+	snap := mirrorSnapshot{
+		Format:     mirrorFormat,
+		Version:    version.Version,
+		LastUpdate: version.LastUpdate,
+		SyncedAt:   time.Now().UTC(),
+		Cards:      cards,
+	}
+	if err := os.MkdirAll(filepath.Dir(m.path), 0o755); err != nil {
+		return err
+	}
+	if err := writeJSONFile(m.path, snap); err != nil {
+		return err
+	}
+	m.replace(snap)
+	log.Printf("✅ Miroir synchronisé: %d cartes", len(cards))
+	return nil
+}
+
+// run synchronise le miroir immédiatement puis à intervalle régulier
+func (m *cardMirror) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		syncCtx, cancel := context.WithTimeout(ctx, syncTimeout)
+		if err := m.sync(syncCtx); err != nil {
+			log.Printf("⚠️ Synchronisation du miroir impossible: %v", err)
+		}
+		cancel()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *cardMirror) replace(snap mirrorSnapshot) {
+	byID := make(map[int]int, len(snap.Cards))
+	byName := make(map[string]int, len(snap.Cards))
+	for i, c := range snap.Cards {
+		byID[c.ID] = i
+		byName[strings.ToLower(c.Name)] = i
+		// Les illustrations alternatives ont leur propre code
+		for _, img := range c.Images {
+			if _, ok := byID[img.ID]; !ok {
+				byID[img.ID] = i
+			}
+		}
+	}
+
+	m.mu.Lock()
+	m.snapshot = snap
+	m.byID = byID
+	m.byName = byName
+	listeners := m.listeners
+	m.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn(snap.Cards)
+	}
+}
+
+// all retourne les cartes du miroir, ou nil s'il n'est pas encore synchronisé
+func (m *cardMirror) all() []Card {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.snapshot.Cards
+}
+
+func (m *cardMirror) SearchCards(ctx context.Context, q CardQuery) ([]Card, error) {
+	all := m.all()
+	if all == nil {
+		return m.upstream.SearchCards(ctx, q)
+	}
+
+	result := []Card{}
+	for _, c := range all {
+		if q.match(c) {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+func (m *cardMirror) CardByID(ctx context.Context, id int) (Card, error) {
+	m.mu.RLock()
+	cards, byID := m.snapshot.Cards, m.byID
+	m.mu.RUnlock()
+	if cards == nil {
+		return m.upstream.CardByID(ctx, id)
+	}
+
+	i, ok := byID[id]
+	if !ok {
+		return Card{}, errCardNotFound
+	}
+	return cards[i], nil
+}
+
+func (m *cardMirror) CardByName(ctx context.Context, name string) (Card, error) {
+	m.mu.RLock()
+	cards, byName := m.snapshot.Cards, m.byName
+	m.mu.RUnlock()
+	if cards == nil {
+		return m.upstream.CardByName(ctx, name)
+	}
+
+	i, ok := byName[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return Card{}, errCardNotFound
+	}
+	return cards[i], nil
+}
+
+// Archetypes préfère la liste officielle et se rabat sur les archétypes du
+// miroir quand l'API n'est pas joignable.
+func (m *cardMirror) Archetypes(ctx context.Context) ([]string, error) {
+	archetypes, err := m.upstream.Archetypes(ctx)
+	if err == nil {
+		return archetypes, nil
+	}
+
+	all := m.all()
+	if all == nil {
+		return nil, err
+	}
+	return archetypesOf(all), nil
+}
+
+// archetypesOf retourne la liste triée des archétypes présents dans les cartes
+func archetypesOf(cards []Card) []string {
+	seen := make(map[string]bool)
+	archetypes := []string{}
+	for _, c := range cards {
+		if c.Archetype != "" && !seen[c.Archetype] {
+			seen[c.Archetype] = true
+			archetypes = append(archetypes, c.Archetype)
+		}
+	}
+	sort.Strings(archetypes)
+	return archetypes
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// Taille de page appliquée sans paramètre limit et taille maximale
+const (
+	defaultPageLimit = 100
+	maxPageLimit     = 500
+)
+
+// Pagination décrit la page renvoyée dans APIResponse. NextOffset est absent
+// sur la dernière page.
+type Pagination struct {
+	Total      int  `json:"total"`
+	Limit      int  `json:"limit"`
+	Offset     int  `json:"offset"`
+	NextOffset *int `json:"next_offset,omitempty"`
+}
+
+// parsePagination lit les paramètres limit (defaultPageLimit par défaut,
+// maxPageLimit au plus) et offset
+func parsePagination(values url.Values) (Pagination, error) {
+	p := Pagination{Limit: defaultPageLimit}
+	if v := values.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return p, fmt.Errorf("Paramètre 'limit' invalide: %s", v)
+		}
+		if n > maxPageLimit {
+			n = maxPageLimit
+		}
+		p.Limit = n
+	}
+	if v := values.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return p, fmt.Errorf("Paramètre 'offset' invalide: %s", v)
+		}
+		p.Offset = n
+	}
+	return p, nil
+}
+
+// paginate découpe items selon p et renseigne le total et l'offset suivant
+func paginate[T any](items []T, p *Pagination) []T {
+	p.Total = len(items)
+	if p.Offset >= len(items) {
+		return items[:0]
+	}
+	end := len(items)
+	if p.Limit > 0 && p.Offset+p.Limit < end {
+		end = p.Offset + p.Limit
+		p.NextOffset = &end
+	}
+	return items[p.Offset:end]
+}
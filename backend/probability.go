@@ -0,0 +1,284 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Tailles de main de départ : 5 cartes en premier, 6 en second
+const (
+	handGoingFirst  = 5
+	handGoingSecond = 6
+)
+
+// maxProbabilityGroups borne le nombre de groupes, les combinaisons de
+// groupes se chevauchant croissant en 2^n
+const maxProbabilityGroups = 8
+
+// ProbabilityRequest décrit un deck (deck_id ou main_cards), des groupes de
+// cartes nommés et les scénarios dont on veut la probabilité
+type ProbabilityRequest struct {
+	DeckID    string                `json:"deck_id"`
+	MainCards []string              `json:"main_cards"`
+	Groups    map[string][]string   `json:"groups"`
+	Scenarios []ProbabilityScenario `json:"scenarios"`
+}
+
+// ProbabilityScenario est un ensemble de conditions à réunir dans la même main
+type ProbabilityScenario struct {
+	Name       string           `json:"name"`
+	Conditions []GroupCondition `json:"conditions"`
+}
+
+// GroupCondition exige entre Min et Max cartes du groupe (Max absent : pas de
+// maximum)
+type GroupCondition struct {
+	Group string `json:"group"`
+	Min   int    `json:"min"`
+	Max   *int   `json:"max,omitempty"`
+}
+
+// ProbabilityResult donne la probabilité de chaque scénario en main de départ
+type ProbabilityResult struct {
+	DeckSize  int              `json:"deck_size"`
+	Groups    []GroupCount     `json:"groups"`
+	Scenarios []ScenarioResult `json:"scenarios"`
+}
+
+// GroupCount est le nombre d'exemplaires d'un groupe dans le Main Deck
+type GroupCount struct {
+	Name      string   `json:"name"`
+	Copies    int      `json:"copies"`
+	NotInDeck []string `json:"not_in_deck"`
+}
+
+// ScenarioResult donne la probabilité d'un scénario en main de 5 et de 6
+type ScenarioResult struct {
+	ProbabilityScenario
+	GoingFirst  float64 `json:"going_first"`
+	GoingSecond float64 `json:"going_second"`
+}
+
+// handProbabilities calcule la probabilité exacte de chaque scénario pour une
+// main de handSize cartes tirée sans remise. Le deck est découpé en classes de
+// cartes appartenant aux mêmes groupes, puis une programmation dynamique
+// ajoute les classes une à une (loi hypergéométrique multivariée). L'état est
+// le nombre de cartes tirées et, pour chaque groupe, le nombre de cartes du
+// groupe plafonné à la plus grande valeur que les scénarios distinguent : les
+// mains équivalentes pour tous les scénarios sont ainsi fusionnées.
+func handProbabilities(deck []string, groups []string, members map[string]map[string]bool, scenarios []ProbabilityScenario, handSize int) []float64 {
+	index := make(map[string]int, len(groups))
+	for i, g := range groups {
+		index[g] = i
+	}
+
+	// Au-delà de caps[g] cartes, aucun scénario ne distingue deux mains ; un
+	// groupe jamais cité (plafond nul) est retiré des classes
+	caps := make([]int, len(groups))
+	for _, scenario := range scenarios {
+		for _, c := range scenario.Conditions {
+			need := c.Min
+			if c.Max != nil {
+				need = max(need, *c.Max+1)
+			}
+			g := index[c.Group]
+			caps[g] = max(caps[g], min(need, handSize))
+		}
+	}
+
+	// Nombre de cartes du deck par masque d'appartenance aux groupes
+	classes := make(map[uint]int)
+	for _, name := range deck {
+		var mask uint
+		for i, g := range groups {
+			if caps[i] > 0 && members[g][cardKey(name)] {
+				mask |= 1 << i
+			}
+		}
+		classes[mask]++
+	}
+	masks := make([]uint, 0, len(classes))
+	for mask := range classes {
+		masks = append(masks, mask)
+	}
+	sort.Slice(masks, func(i, j int) bool { return masks[i] < masks[j] })
+
+	// Un état tient sur un entier : 4 bits pour le nombre de cartes tirées
+	// puis 4 bits par groupe (handSize < 16)
+	const bits = 4
+	field := func(state uint64, i int) int { return int(state>>(bits*i)) & (1<<bits - 1) }
+	states := map[uint64]float64{0: 1}
+	for _, mask := range masks {
+		count := classes[mask]
+		next := make(map[uint64]float64, len(states))
+		for state, ways := range states {
+			taken := field(state, 0)
+			for k := 0; k <= count && taken+k <= handSize; k++ {
+				key := uint64(taken + k)
+				for g := range groups {
+					n := field(state, g+1)
+					if mask&(1<<g) != 0 {
+						n = min(n+k, caps[g])
+					}
+					key |= uint64(n) << (bits * (g + 1))
+				}
+				next[key] += ways * binomial(count, k)
+			}
+		}
+		states = next
+	}
+
+	total := binomial(len(deck), handSize)
+	probs := make([]float64, len(scenarios))
+	drawn := make([]int, len(groups))
+	for state, ways := range states {
+		if field(state, 0) != handSize {
+			continue
+		}
+		for g := range drawn {
+			drawn[g] = field(state, g+1)
+		}
+		for s, scenario := range scenarios {
+			if satisfied(scenario, drawn, index) {
+				probs[s] += ways / total
+			}
+		}
+	}
+
+	for s := range probs {
+		probs[s] = math.Round(probs[s]*1e6) / 1e6
+	}
+	return probs
+}
+
+func satisfied(s ProbabilityScenario, drawn []int, index map[string]int) bool {
+	for _, c := range s.Conditions {
+		n := drawn[index[c.Group]]
+		if n < c.Min || (c.Max != nil && n > *c.Max) {
+			return false
+		}
+	}
+	return true
+}
+
+// binomial retourne C(n, k) en flottant, exact pour les tailles de deck
+func binomial(n, k int) float64 {
+	if k < 0 || k > n {
+		return 0
+	}
+	k = min(k, n-k)
+	result := 1.0
+	for i := 1; i <= k; i++ {
+		result = result * float64(n-k+i) / float64(i)
+	}
+	return math.Round(result)
+}
+
+// decodeProbabilityRequest lit la requête et retrouve le Main Deck à utiliser
+func decodeProbabilityRequest(w http.ResponseWriter, r *http.Request) (ProbabilityRequest, error) {
+	var req ProbabilityRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxDeckBody)).Decode(&req); err != nil {
+		return req, errors.New("Requête invalide: " + err.Error())
+	}
+	if req.DeckID != "" {
+		d, ok := findDeck(req.DeckID)
+		if !ok {
+			return req, errors.New("Deck non trouvé: " + req.DeckID)
+		}
+		req.MainCards = d.MainCards
+	}
+	if len(req.MainCards) < handGoingSecond || len(req.MainCards) > maxMainDeck {
+		return req, fmt.Errorf("Le Main Deck doit contenir entre %d et %d cartes", handGoingSecond, maxMainDeck)
+	}
+	if len(req.Groups) == 0 || len(req.Groups) > maxProbabilityGroups {
+		return req, fmt.Errorf("Entre 1 et %d groupes de cartes requis", maxProbabilityGroups)
+	}
+
+	// Sans scénario, probabilité d'ouvrir au moins une carte de chaque groupe
+	if len(req.Scenarios) == 0 {
+		for _, g := range sortedKeys(req.Groups) {
+			req.Scenarios = append(req.Scenarios, ProbabilityScenario{
+				Name:       "1+ " + g,
+				Conditions: []GroupCondition{{Group: g, Min: 1}},
+			})
+		}
+	}
+	for i, s := range req.Scenarios {
+		if s.Name == "" {
+			req.Scenarios[i].Name = fmt.Sprintf("scénario %d", i+1)
+		}
+		for _, c := range s.Conditions {
+			if _, ok := req.Groups[c.Group]; !ok {
+				return req, fmt.Errorf("Groupe inconnu dans %q: %s", req.Scenarios[i].Name, c.Group)
+			}
+			if c.Min < 0 || (c.Max != nil && *c.Max < c.Min) {
+				return req, fmt.Errorf("Condition invalide sur %s dans %q", c.Group, req.Scenarios[i].Name)
+			}
+		}
+	}
+	return req, nil
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// getHandProbability calcule en POST les chances d'ouvrir les scénarios
+// demandés en main de 5 (en premier) et de 6 (en second)
+func getHandProbability(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSON(w, http.StatusMethodNotAllowed, APIResponse{Error: "Méthode non autorisée", Status: "error"})
+		return
+	}
+	req, err := decodeProbabilityRequest(w, r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, APIResponse{Error: err.Error(), Status: "error"})
+		return
+	}
+
+	inDeck := make(map[string]int)
+	for _, name := range req.MainCards {
+		inDeck[cardKey(name)]++
+	}
+	groups := sortedKeys(req.Groups)
+	members := make(map[string]map[string]bool, len(groups))
+	result := ProbabilityResult{DeckSize: len(req.MainCards), Groups: []GroupCount{}, Scenarios: []ScenarioResult{}}
+	for _, g := range groups {
+		count := GroupCount{Name: g, NotInDeck: []string{}}
+		members[g] = make(map[string]bool)
+		for _, name := range req.Groups[g] {
+			key := cardKey(name)
+			if members[g][key] {
+				continue
+			}
+			members[g][key] = true
+			if inDeck[key] == 0 {
+				count.NotInDeck = append(count.NotInDeck, strings.TrimSpace(name))
+			}
+			count.Copies += inDeck[key]
+		}
+		result.Groups = append(result.Groups, count)
+	}
+
+	first := handProbabilities(req.MainCards, groups, members, req.Scenarios, handGoingFirst)
+	second := handProbabilities(req.MainCards, groups, members, req.Scenarios, handGoingSecond)
+	for i, s := range req.Scenarios {
+		result.Scenarios = append(result.Scenarios, ScenarioResult{ProbabilityScenario: s, GoingFirst: first[i], GoingSecond: second[i]})
+	}
+
+	writeJSON(w, http.StatusOK, APIResponse{Data: result, Status: "success"})
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// testDeck construit un Main Deck à partir d'un nombre d'exemplaires par nom,
+// complété par des cartes "Filler" jusqu'à size
+func testDeck(size int, copies map[string]int) []string {
+	var deck []string
+	for name, n := range copies {
+		for i := 0; i < n; i++ {
+			deck = append(deck, name)
+		}
+	}
+	for len(deck) < size {
+		deck = append(deck, "Filler")
+	}
+	return deck
+}
+
+func testMembers(groups map[string][]string) map[string]map[string]bool {
+	members := make(map[string]map[string]bool)
+	for g, names := range groups {
+		members[g] = make(map[string]bool)
+		for _, name := range names {
+			members[g][cardKey(name)] = true
+		}
+	}
+	return members
+}
+
+func intPtr(n int) *int { return &n }
+
+func TestHandProbabilities(t *testing.T) {
+	deck := testDeck(40, map[string]int{"Ash": 3, "Starter A": 3, "Starter B": 3, "Starter C": 3, "Extender": 6})
+	groups := map[string][]string{
+		"ash":      {"Ash"},
+		"starters": {"Starter A", "Starter B", "Starter C"},
+		"extender": {"Extender"},
+	}
+	// Valeurs de la loi hypergéométrique calculées à part, par exemple
+	// 1 - C(37,5)/C(40,5) pour au moins une carte sur 3 exemplaires
+	tests := []struct {
+		name       string
+		conditions []GroupCondition
+		handSize   int
+		want       float64
+	}{
+		{"1+ sur 3 exemplaires en 5", []GroupCondition{{Group: "ash", Min: 1}}, 5, 0.337551},
+		{"1+ sur 3 exemplaires en 6", []GroupCondition{{Group: "ash", Min: 1}}, 6, 0.394332},
+		{"1+ sur 9 exemplaires", []GroupCondition{{Group: "starters", Min: 1}}, 5, 0.74178},
+		{"exactement 2", []GroupCondition{{Group: "ash", Min: 2, Max: intPtr(2)}}, 5, 0.035425},
+		{"aucun", []GroupCondition{{Group: "ash", Max: intPtr(0)}}, 5, 1 - 0.337551},
+		{"deux groupes", []GroupCondition{{Group: "ash", Min: 1}, {Group: "extender", Min: 1}}, 5, 0.172895},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scenarios := []ProbabilityScenario{{Name: tt.name, Conditions: tt.conditions}}
+			got := handProbabilities(deck, sortedKeys(groups), testMembers(groups), scenarios, tt.handSize)
+			if math.Abs(got[0]-tt.want) > 2e-6 {
+				t.Errorf("probabilité = %v, want %v", got[0], tt.want)
+			}
+		})
+	}
+}
+
+// TestHandProbabilitiesOverlap compare les groupes qui se chevauchent à une
+// énumération de toutes les mains possibles
+func TestHandProbabilitiesOverlap(t *testing.T) {
+	deck := testDeck(14, map[string]int{"A": 3, "B": 2, "C": 3, "D": 1})
+	groups := map[string][]string{
+		"ab":  {"A", "B"},
+		"bc":  {"B", "C"},
+		"abd": {"A", "B", "D"},
+	}
+	scenarios := []ProbabilityScenario{
+		{Name: "ab et bc", Conditions: []GroupCondition{{Group: "ab", Min: 1}, {Group: "bc", Min: 1}}},
+		{Name: "2+ bc sans abd", Conditions: []GroupCondition{{Group: "bc", Min: 2}, {Group: "abd", Max: intPtr(0)}}},
+		{Name: "1 à 2 abd", Conditions: []GroupCondition{{Group: "abd", Min: 1, Max: intPtr(2)}}},
+	}
+	members := testMembers(groups)
+
+	for _, handSize := range []int{handGoingFirst, handGoingSecond} {
+		hits := make([]int, len(scenarios))
+		total := 0
+		hand := make([]int, 0, handSize)
+		var enumerate func(start int)
+		enumerate = func(start int) {
+			if len(hand) == handSize {
+				total++
+				for s, scenario := range scenarios {
+					ok := true
+					for _, c := range scenario.Conditions {
+						n := 0
+						for _, i := range hand {
+							if members[c.Group][cardKey(deck[i])] {
+								n++
+							}
+						}
+						if n < c.Min || (c.Max != nil && n > *c.Max) {
+							ok = false
+						}
+					}
+					if ok {
+						hits[s]++
+					}
+				}
+				return
+			}
+			for i := start; i < len(deck); i++ {
+				hand = append(hand, i)
+				enumerate(i + 1)
+				hand = hand[:len(hand)-1]
+			}
+		}
+		enumerate(0)
+
+		got := handProbabilities(deck, sortedKeys(groups), members, scenarios, handSize)
+		for s, scenario := range scenarios {
+			want := float64(hits[s]) / float64(total)
+			if math.Abs(got[s]-want) > 1e-6 {
+				t.Errorf("main de %d, %s: %v, want %v", handSize, scenario.Name, got[s], want)
+			}
+		}
+	}
+}
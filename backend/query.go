@@ -0,0 +1,341 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CardQuery décrit une recherche de cartes multi-critères. Tous les critères
+// renseignés doivent être satisfaits.
+type CardQuery struct {
+	Name        string // recherche partielle sur le nom (fname)
+	Archetype   string
+	Types       []string // fragments du type, ex. "tuner" ou "synchro"
+	Attribute   string   // LIGHT, DARK...
+	Race        string   // Fairy, Spellcaster, Continuous...
+	Level       intRange // niveau ou rang
+	ATK         statRange
+	DEF         statRange
+	Link        intRange
+	LinkMarkers []string // toutes les flèches demandées doivent être présentes
+	Scale       intRange
+	Banlist     string // tcg, ocg ou goat : cartes présentes sur cette liste
+	SetCode     string // préfixe de code de set, ex. "LEDE" ou "LEDE-EN001"
+
+	Sort string // name, atk, def, level, id ou date (sortie TCG)
+	Desc bool
+}
+
+// intRange est un intervalle fermé dont chaque borne est optionnelle
+type intRange struct {
+	Min *int
+	Max *int
+}
+
+func (r intRange) set() bool {
+	return r.Min != nil || r.Max != nil
+}
+
+func (r intRange) contains(v int) bool {
+	if r.Min != nil && v < *r.Min {
+		return false
+	}
+	if r.Max != nil && v > *r.Max {
+		return false
+	}
+	return true
+}
+
+// param traduit l'intervalle dans la syntaxe YGOProDeck (4, gte4, lte4). Quand
+// les deux bornes sont fixées seule la borne basse est envoyée, la borne haute
+// étant vérifiée localement.
+func (r intRange) param() string {
+	switch {
+	case r.Min != nil && r.Max != nil && *r.Min == *r.Max:
+		return strconv.Itoa(*r.Min)
+	case r.Min != nil:
+		return "gte" + strconv.Itoa(*r.Min)
+	case r.Max != nil:
+		return "lte" + strconv.Itoa(*r.Max)
+	}
+	return ""
+}
+
+// statRange filtre l'ATK ou la DEF. Unknown sélectionne les valeurs "?", que
+// YGOProDeck encode par une valeur négative.
+type statRange struct {
+	intRange
+	Unknown bool
+}
+
+func (r statRange) set() bool {
+	return r.Unknown || r.intRange.set()
+}
+
+func (r statRange) contains(v int) bool {
+	if v < 0 {
+		return r.Unknown
+	}
+	if r.Unknown && !r.intRange.set() {
+		return false
+	}
+	return r.intRange.contains(v)
+}
+
+// cardSortKeys liste les clés de tri acceptées par le paramètre sort
+var cardSortKeys = map[string]bool{"name": true, "atk": true, "def": true, "level": true, "id": true, "date": true}
+
+// parseCardQuery lit les critères de recherche depuis les paramètres d'URL
+func parseCardQuery(values url.Values) (CardQuery, error) {
+	q := CardQuery{
+		Name:      strings.TrimSpace(values.Get("q")),
+		Archetype: strings.TrimSpace(values.Get("archetype")),
+		Attribute: strings.TrimSpace(values.Get("attribute")),
+		Race:      strings.TrimSpace(values.Get("race")),
+		Banlist:   strings.ToLower(strings.TrimSpace(values.Get("banlist"))),
+		SetCode:   strings.TrimSpace(values.Get("set")),
+		Sort:      strings.ToLower(values.Get("sort")),
+		Desc:      strings.EqualFold(values.Get("order"), "desc"),
+	}
+	// Ancien nom du paramètre, conservé pour le frontend
+	if q.Archetype == "" {
+		q.Archetype = strings.TrimSpace(values.Get("archtype"))
+	}
+	q.Types = splitList(values.Get("type"))
+	q.LinkMarkers = splitList(values.Get("linkmarker"))
+
+	var err error
+	if q.Level, err = parseRange(values, "level"); err != nil {
+		return q, err
+	}
+	if q.Link, err = parseRange(values, "link"); err != nil {
+		return q, err
+	}
+	if q.Scale, err = parseRange(values, "scale"); err != nil {
+		return q, err
+	}
+	if q.ATK, err = parseStatRange(values, "atk"); err != nil {
+		return q, err
+	}
+	if q.DEF, err = parseStatRange(values, "def"); err != nil {
+		return q, err
+	}
+
+	switch q.Banlist {
+	case "", "tcg", "ocg", "goat":
+	default:
+		return q, fmt.Errorf("Paramètre 'banlist' invalide: %s (tcg, ocg ou goat)", q.Banlist)
+	}
+	if q.Sort != "" && !cardSortKeys[q.Sort] {
+		return q, fmt.Errorf("Paramètre 'sort' invalide: %s", q.Sort)
+	}
+	return q, nil
+}
+
+// parseRange lit <name>, <name>_min et <name>_max
+func parseRange(values url.Values, name string) (intRange, error) {
+	var r intRange
+	if v := values.Get(name); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return r, fmt.Errorf("Paramètre '%s' invalide: %s", name, v)
+		}
+		r.Min, r.Max = &n, &n
+	}
+	for _, bound := range []struct {
+		suffix string
+		dst    **int
+	}{{"_min", &r.Min}, {"_max", &r.Max}} {
+		v := values.Get(name + bound.suffix)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return r, fmt.Errorf("Paramètre '%s%s' invalide: %s", name, bound.suffix, v)
+		}
+		*bound.dst = &n
+	}
+	return r, nil
+}
+
+// parseStatRange accepte en plus la valeur "?" pour <name>
+func parseStatRange(values url.Values, name string) (statRange, error) {
+	if values.Get(name) == "?" {
+		rest := url.Values{name + "_min": values[name+"_min"], name + "_max": values[name+"_max"]}
+		r, err := parseRange(rest, name)
+		return statRange{intRange: r, Unknown: true}, err
+	}
+	r, err := parseRange(values, name)
+	return statRange{intRange: r}, err
+}
+
+// splitList découpe une liste séparée par des virgules
+func splitList(v string) []string {
+	var out []string
+	for _, part := range strings.Split(v, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// empty indique qu'aucun critère de filtrage n'est renseigné
+func (q CardQuery) empty() bool {
+	return q.Name == "" && q.Archetype == "" && len(q.Types) == 0 && q.Attribute == "" &&
+		q.Race == "" && !q.Level.set() && !q.ATK.set() && !q.DEF.set() && !q.Link.set() &&
+		len(q.LinkMarkers) == 0 && !q.Scale.set() && q.Banlist == "" && q.SetCode == ""
+}
+
+// match indique si la carte satisfait tous les critères de la requête
+func (q CardQuery) match(c Card) bool {
+	if q.Name != "" && !strings.Contains(strings.ToLower(c.Name), strings.ToLower(q.Name)) {
+		return false
+	}
+	if q.Archetype != "" && !strings.EqualFold(c.Archetype, q.Archetype) {
+		return false
+	}
+	cardType := strings.ToLower(c.Type)
+	for _, t := range q.Types {
+		if !strings.Contains(cardType, strings.ToLower(t)) {
+			return false
+		}
+	}
+	if q.Attribute != "" && !strings.EqualFold(c.Attribute, q.Attribute) {
+		return false
+	}
+	if q.Race != "" && !strings.EqualFold(c.Race, q.Race) {
+		return false
+	}
+
+	monster := c.isMonster()
+	if q.Level.set() && (!monster || c.Level == 0 || !q.Level.contains(c.Level)) {
+		return false
+	}
+	if q.ATK.set() && (!monster || !q.ATK.contains(c.ATK)) {
+		return false
+	}
+	// Les monstres Lien n'ont pas de DEF
+	if q.DEF.set() && (!monster || c.LinkVal > 0 || !q.DEF.contains(c.DEF)) {
+		return false
+	}
+	if q.Link.set() && (c.LinkVal == 0 || !q.Link.contains(c.LinkVal)) {
+		return false
+	}
+	for _, marker := range q.LinkMarkers {
+		if !containsFold(c.LinkMarkers, marker) {
+			return false
+		}
+	}
+	if q.Scale.set() && (c.Scale == nil || !q.Scale.contains(*c.Scale)) {
+		return false
+	}
+	if q.Banlist != "" && c.banStatus(q.Banlist) == "" {
+		return false
+	}
+	if q.SetCode != "" && !c.inSet(q.SetCode) {
+		return false
+	}
+	return true
+}
+
+// params traduit en paramètres cardinfo.php les critères que YGOProDeck sait
+// filtrer. Les autres sont appliqués localement par match.
+func (q CardQuery) params() url.Values {
+	params := url.Values{"misc": {"yes"}}
+	if q.Name != "" {
+		params.Set("fname", q.Name)
+	}
+	if q.Archetype != "" {
+		params.Set("archetype", q.Archetype)
+	}
+	if q.Attribute != "" {
+		params.Set("attribute", strings.ToLower(q.Attribute))
+	}
+	if q.Race != "" {
+		params.Set("race", q.Race)
+	}
+	if q.Level.set() {
+		params.Set("level", q.Level.param())
+	}
+	if !q.ATK.Unknown && q.ATK.intRange.set() {
+		params.Set("atk", q.ATK.param())
+	}
+	if !q.DEF.Unknown && q.DEF.intRange.set() {
+		params.Set("def", q.DEF.param())
+	}
+	if q.Link.set() {
+		params.Set("link", q.Link.param())
+	}
+	if q.Scale.set() {
+		params.Set("scale", q.Scale.param())
+	}
+	if q.Banlist != "" {
+		params.Set("banlist", q.Banlist)
+	}
+	return params
+}
+
+// sortCards trie les cartes selon la clé de tri de la requête (par nom par
+// défaut)
+func (q CardQuery) sortCards(cards []Card) {
+	sort.SliceStable(cards, func(i, j int) bool { return q.less(cards[i], cards[j]) })
+}
+
+// less compare deux cartes selon la clé de tri de la requête. Les égalités
+// sont départagées par nom puis par identifiant pour que l'ordre soit stable
+// d'une page à l'autre.
+func (q CardQuery) less(a, b Card) bool {
+	var c int
+	switch q.Sort {
+	case "atk":
+		c = a.ATK - b.ATK
+	case "def":
+		c = a.DEF - b.DEF
+	case "level":
+		c = a.Level - b.Level
+	case "id":
+		c = a.ID - b.ID
+	case "date":
+		c = compareDates(a.releaseDate(), b.releaseDate())
+	}
+	if c != 0 {
+		if q.Desc {
+			return c > 0
+		}
+		return c < 0
+	}
+	if an, bn := strings.ToLower(a.Name), strings.ToLower(b.Name); an != bn {
+		if q.Sort == "" || q.Sort == "name" {
+			return (an < bn) != q.Desc
+		}
+		return an < bn
+	}
+	return a.ID < b.ID
+}
+
+// compareDates compare deux dates AAAA-MM-JJ, les dates inconnues en dernier
+func compareDates(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+	return strings.Compare(a, b)
+}
+
+func containsFold(list []string, v string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,342 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Bornes du simulateur
+const (
+	defaultSimulatedHands = 10000
+	maxSimulatedHands     = 200000
+	defaultSampleHands    = 5
+	maxSampleHands        = 20
+	maxHandRules          = 20
+
+	// simulationShards est fixe afin que le résultat ne dépende que de la
+	// graine, quel que soit le nombre de cœurs
+	simulationShards = 16
+)
+
+// SimulationRequest décrit un deck (deck_id ou main_cards), des groupes de
+// cartes et les règles de main jouable à évaluer
+type SimulationRequest struct {
+	DeckID    string              `json:"deck_id"`
+	MainCards []string            `json:"main_cards"`
+	Groups    map[string][]string `json:"groups"`
+	Rules     []HandRule          `json:"rules"`
+	Hands     int                 `json:"hands"`
+	HandSize  int                 `json:"hand_size"` // 5 (par défaut) ou 6
+	Seed      *int64              `json:"seed"`      // aléatoire si absente
+	Samples   *int                `json:"samples"`
+}
+
+// HandRule est une règle de main jouable, ex. `starters >= 1 && !bricks`
+type HandRule struct {
+	Name string `json:"name"`
+	Expr string `json:"expr"`
+}
+
+// SimulationResult est le résultat d'une simulation, reproductible avec Seed
+type SimulationResult struct {
+	Seed          int64           `json:"seed"`
+	Hands         int             `json:"hands"`
+	HandSize      int             `json:"hand_size"`
+	DeckSize      int             `json:"deck_size"`
+	SampleHands   [][]string      `json:"sample_hands"`
+	CardFrequency []CardFrequency `json:"card_frequency"`
+	Rules         []RuleResult    `json:"rules"`
+}
+
+// CardFrequency donne la part des mains contenant au moins un exemplaire
+type CardFrequency struct {
+	Name   string  `json:"name"`
+	Copies int     `json:"copies"`
+	Rate   float64 `json:"rate"`
+}
+
+// RuleResult donne la part des mains satisfaisant une règle
+type RuleResult struct {
+	HandRule
+	Hits int     `json:"hits"`
+	Rate float64 `json:"rate"`
+}
+
+// simulation est une simulation prête à tourner : cartes du deck indexées,
+// termes de chaque carte et règles compilées
+type simulation struct {
+	deck     []int // index de la carte distincte pour chaque exemplaire
+	names    []string
+	cardTerm [][]int // termes (groupes, cartes citées) de chaque carte distincte
+	terms    int
+	rules    []handExpr
+	handSize int
+}
+
+// shardResult accumule les résultats d'une tranche de mains
+type shardResult struct {
+	cardHits []int
+	ruleHits []int
+	samples  [][]int
+}
+
+// run simule hands mains réparties en simulationShards tranches, chacune avec
+// sa propre graine dérivée de seed, exécutées en parallèle
+func (s *simulation) run(seed int64, hands, samples int) []shardResult {
+	results := make([]shardResult, simulationShards)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(runtime.GOMAXPROCS(0), simulationShards); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for shard := range jobs {
+				n := hands / simulationShards
+				if shard < hands%simulationShards {
+					n++
+				}
+				// Les mains d'exemple sont réparties comme les mains : comme
+				// samples <= hands, chaque tranche en tire assez
+				keep := samples / simulationShards
+				if shard < samples%simulationShards {
+					keep++
+				}
+				results[shard] = s.runShard(shardSeed(seed, shard), n, keep)
+			}
+		}()
+	}
+	for shard := 0; shard < simulationShards; shard++ {
+		jobs <- shard
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// shardSeed dérive la graine d'une tranche par splitmix64 : des graines
+// voisines donnent des tirages sans rapport entre eux
+func shardSeed(seed int64, shard int) int64 {
+	z := uint64(seed) ^ uint64(shard)*0x9E3779B97F4A7C15
+	z += 0x9E3779B97F4A7C15
+	z = (z ^ z>>30) * 0xBF58476D1CE4E5B9
+	z = (z ^ z>>27) * 0x94D049BB133111EB
+	return int64(z ^ z>>31)
+}
+
+func (s *simulation) runShard(seed int64, hands, keep int) shardResult {
+	rng := rand.New(rand.NewSource(seed))
+	res := shardResult{cardHits: make([]int, len(s.names)), ruleHits: make([]int, len(s.rules))}
+	deck := make([]int, len(s.deck))
+	counts := make([]int, s.terms)
+	seen := make([]bool, len(s.names))
+
+	for h := 0; h < hands; h++ {
+		// Tirage sans remise : mélange partiel des premières cartes
+		copy(deck, s.deck)
+		for i := 0; i < s.handSize; i++ {
+			j := i + rng.Intn(len(deck)-i)
+			deck[i], deck[j] = deck[j], deck[i]
+		}
+		hand := deck[:s.handSize]
+
+		for i := range counts {
+			counts[i] = 0
+		}
+		for i := range seen {
+			seen[i] = false
+		}
+		for _, card := range hand {
+			if !seen[card] {
+				seen[card] = true
+				res.cardHits[card]++
+			}
+			for _, t := range s.cardTerm[card] {
+				counts[t]++
+			}
+		}
+		for i, rule := range s.rules {
+			if rule.eval(counts) {
+				res.ruleHits[i]++
+			}
+		}
+		if h < keep {
+			res.samples = append(res.samples, append([]int(nil), hand...))
+		}
+	}
+	return res
+}
+
+// newSimulation indexe le deck et compile les règles
+func newSimulation(req SimulationRequest) (*simulation, error) {
+	groups := sortedKeys(req.Groups)
+	terms := newHandTerms(groups)
+	s := &simulation{handSize: req.HandSize}
+	for _, rule := range req.Rules {
+		e, err := parseHandExpr(rule.Expr, terms)
+		if err != nil {
+			return nil, fmt.Errorf("Règle %q invalide: %v", rule.Name, err)
+		}
+		s.rules = append(s.rules, e)
+	}
+	s.terms = terms.count
+
+	index := make(map[string]int)
+	for _, name := range req.MainCards {
+		key := cardKey(name)
+		i, ok := index[key]
+		if !ok {
+			i = len(s.names)
+			index[key] = i
+			s.names = append(s.names, strings.TrimSpace(name))
+
+			var cardTerms []int
+			for _, g := range groups {
+				for _, member := range req.Groups[g] {
+					if cardKey(member) == key {
+						cardTerms = append(cardTerms, terms.groups[g])
+						break
+					}
+				}
+			}
+			if t, ok := terms.cards[key]; ok {
+				cardTerms = append(cardTerms, t)
+			}
+			s.cardTerm = append(s.cardTerm, cardTerms)
+		}
+		s.deck = append(s.deck, i)
+	}
+	return s, nil
+}
+
+// decodeSimulationRequest lit la requête et applique les valeurs par défaut
+func decodeSimulationRequest(w http.ResponseWriter, r *http.Request) (SimulationRequest, error) {
+	var req SimulationRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxDeckBody)).Decode(&req); err != nil {
+		return req, errors.New("Requête invalide: " + err.Error())
+	}
+	if req.DeckID != "" {
+		d, ok := findDeck(req.DeckID)
+		if !ok {
+			return req, errors.New("Deck non trouvé: " + req.DeckID)
+		}
+		req.MainCards = d.MainCards
+	}
+
+	switch req.HandSize {
+	case 0:
+		req.HandSize = handGoingFirst
+	case handGoingFirst, handGoingSecond:
+	default:
+		return req, fmt.Errorf("Paramètre 'hand_size' invalide: %d (5 ou 6)", req.HandSize)
+	}
+	if len(req.MainCards) < req.HandSize || len(req.MainCards) > maxMainDeck {
+		return req, fmt.Errorf("Le Main Deck doit contenir entre %d et %d cartes", req.HandSize, maxMainDeck)
+	}
+	if len(req.Rules) > maxHandRules {
+		return req, fmt.Errorf("%d règles au maximum", maxHandRules)
+	}
+	switch {
+	case req.Hands == 0:
+		req.Hands = defaultSimulatedHands
+	case req.Hands < 0:
+		return req, fmt.Errorf("Paramètre 'hands' invalide: %d", req.Hands)
+	default:
+		req.Hands = min(req.Hands, maxSimulatedHands)
+	}
+	if req.Seed == nil {
+		seed := time.Now().UnixNano()
+		req.Seed = &seed
+	}
+	if req.Samples == nil {
+		n := defaultSampleHands
+		req.Samples = &n
+	} else if *req.Samples < 0 {
+		return req, fmt.Errorf("Paramètre 'samples' invalide: %d", *req.Samples)
+	}
+	*req.Samples = min(*req.Samples, maxSampleHands, req.Hands)
+	for i := range req.Rules {
+		if len(req.Rules[i].Expr) > maxExprLength {
+			return req, fmt.Errorf("Règle n°%d trop longue (%d caractères au maximum)", i+1, maxExprLength)
+		}
+		if req.Rules[i].Name == "" {
+			req.Rules[i].Name = req.Rules[i].Expr
+		}
+	}
+	return req, nil
+}
+
+// simulateHands tire en POST des milliers de mains de départ et mesure la
+// fréquence de chaque carte et des règles de main jouable
+func simulateHands(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSON(w, http.StatusMethodNotAllowed, APIResponse{Error: "Méthode non autorisée", Status: "error"})
+		return
+	}
+	req, err := decodeSimulationRequest(w, r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, APIResponse{Error: err.Error(), Status: "error"})
+		return
+	}
+	sim, err := newSimulation(req)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, APIResponse{Error: err.Error(), Status: "error"})
+		return
+	}
+
+	shards := sim.run(*req.Seed, req.Hands, *req.Samples)
+	result := SimulationResult{
+		Seed:        *req.Seed,
+		Hands:       req.Hands,
+		HandSize:    req.HandSize,
+		DeckSize:    len(req.MainCards),
+		SampleHands: [][]string{},
+		Rules:       []RuleResult{},
+	}
+	cardHits := make([]int, len(sim.names))
+	ruleHits := make([]int, len(sim.rules))
+	for _, shard := range shards {
+		for i, n := range shard.cardHits {
+			cardHits[i] += n
+		}
+		for i, n := range shard.ruleHits {
+			ruleHits[i] += n
+		}
+		for _, hand := range shard.samples {
+			names := make([]string, len(hand))
+			for i, card := range hand {
+				names[i] = sim.names[card]
+			}
+			result.SampleHands = append(result.SampleHands, names)
+		}
+	}
+
+	rate := func(n int) float64 { return math.Round(float64(n)/float64(req.Hands)*1e4) / 1e4 }
+	copies := make([]int, len(sim.names))
+	for _, card := range sim.deck {
+		copies[card]++
+	}
+	for i, name := range sim.names {
+		result.CardFrequency = append(result.CardFrequency, CardFrequency{Name: name, Copies: copies[i], Rate: rate(cardHits[i])})
+	}
+	sort.SliceStable(result.CardFrequency, func(i, j int) bool {
+		return result.CardFrequency[i].Rate > result.CardFrequency[j].Rate
+	})
+	for i, rule := range req.Rules {
+		result.Rules = append(result.Rules, RuleResult{HandRule: rule, Hits: ruleHits[i], Rate: rate(ruleHits[i])})
+	}
+
+	writeJSON(w, http.StatusOK, APIResponse{Data: result, Status: "success"})
+}
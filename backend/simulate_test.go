@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func testSimulation(t *testing.T) *simulation {
+	t.Helper()
+	sim, err := newSimulation(SimulationRequest{
+		MainCards: testDeck(40, map[string]int{"Ash": 3, "Starter A": 3, "Starter B": 3, "Brick": 2}),
+		Groups:    map[string][]string{"starters": {"Starter A", "Starter B"}},
+		Rules: []HandRule{
+			{Name: "ash", Expr: `"Ash"`},
+			{Name: "jouable", Expr: `starters && !"Brick"`},
+		},
+		HandSize: handGoingFirst,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sim
+}
+
+func TestSimulationDeterministic(t *testing.T) {
+	sim := testSimulation(t)
+	first := sim.run(42, 5000, 20)
+
+	// Même graine : mêmes résultats, quel que soit le nombre de workers
+	previous := runtime.GOMAXPROCS(1)
+	again := sim.run(42, 5000, 20)
+	runtime.GOMAXPROCS(previous)
+	if !reflect.DeepEqual(first, again) {
+		t.Error("deux simulations de même graine diffèrent")
+	}
+
+	if reflect.DeepEqual(first, sim.run(43, 5000, 20)) {
+		t.Error("deux graines différentes donnent les mêmes résultats")
+	}
+}
+
+func TestSimulationSamples(t *testing.T) {
+	sim := testSimulation(t)
+	tests := []struct{ hands, samples int }{
+		{50, 20},
+		{16, 16},
+		{5, 5},
+		{10000, 0},
+	}
+	for _, tt := range tests {
+		n := 0
+		for _, shard := range sim.run(1, tt.hands, tt.samples) {
+			n += len(shard.samples)
+		}
+		if n != tt.samples {
+			t.Errorf("hands=%d samples=%d: %d mains d'exemple", tt.hands, tt.samples, n)
+		}
+	}
+}
+
+func TestSimulationRates(t *testing.T) {
+	sim := testSimulation(t)
+	const hands = 100000
+	hits := 0
+	for _, shard := range sim.run(7, hands, 0) {
+		hits += shard.ruleHits[0]
+	}
+	// 1 - C(37,5)/C(40,5) : au moins un des 3 exemplaires en main de 5
+	if rate := float64(hits) / hands; math.Abs(rate-0.337551) > 0.01 {
+		t.Errorf("taux simulé = %v, attendu proche de 0.337551", rate)
+	}
+}
+
+func TestSimulationSeedsIndependent(t *testing.T) {
+	sim := testSimulation(t)
+	a, b := sim.run(42, 1600, 0), sim.run(43, 1600, 0)
+	shared := 0
+	for i := range a {
+		for j := range b {
+			if reflect.DeepEqual(a[i], b[j]) {
+				shared++
+			}
+		}
+	}
+	if shared > 0 {
+		t.Errorf("graines 42 et 43 : %d tranches identiques", shared)
+	}
+}
+
+// Une carte citée entre guillemets ne doit pas se confondre avec un groupe
+// de même nom
+func TestSimulationCardGroupNamespaces(t *testing.T) {
+	sim, err := newSimulation(SimulationRequest{
+		MainCards: []string{"Ash", "Ash", "Ash", "Veiler", "Veiler", "Veiler"},
+		Groups:    map[string][]string{"ash": {"Veiler"}},
+		Rules:     []HandRule{{Name: "carte", Expr: `"Ash" >= 1 && ash == 0`}},
+		HandSize:  3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hits := 0
+	for _, shard := range sim.run(1, 1000, 0) {
+		hits += shard.ruleHits[0]
+	}
+	// Seule la main Ash, Ash, Ash satisfait la règle : 1/C(6,3) = 5 %
+	if rate := float64(hits) / 1000; rate < 0.02 || rate > 0.09 {
+		t.Errorf("taux = %v, attendu proche de 0.05", rate)
+	}
+}
+
+func TestDecodeSimulationRequestLimits(t *testing.T) {
+	rules := make([]HandRule, maxHandRules+1)
+	for i := range rules {
+		rules[i] = HandRule{Expr: `"A"`}
+	}
+	tests := []struct {
+		name string
+		req  SimulationRequest
+		ok   bool
+	}{
+		{"deck valide", SimulationRequest{MainCards: testDeck(40, nil)}, true},
+		{"deck trop petit", SimulationRequest{MainCards: testDeck(4, nil)}, false},
+		{"deck trop grand", SimulationRequest{MainCards: testDeck(maxMainDeck+1, nil)}, false},
+		{"trop de règles", SimulationRequest{MainCards: testDeck(40, nil), Rules: rules}, false},
+		{"règle trop longue", SimulationRequest{MainCards: testDeck(40, nil), Rules: []HandRule{{Expr: strings.Repeat("(", maxExprLength+1)}}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(tt.req)
+			r := httptest.NewRequest(http.MethodPost, "/api/decks/simulate", bytes.NewReader(body))
+			_, err := decodeSimulationRequest(httptest.NewRecorder(), r)
+			if (err == nil) != tt.ok {
+				t.Errorf("erreur = %v, ok attendu %v", err, tt.ok)
+			}
+		})
+	}
+}
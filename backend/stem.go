@@ -0,0 +1,270 @@
+package main
+
+// stem réduit un mot anglais en minuscules à sa racine selon l'algorithme de
+// Porter (1980). Les mots contenant autre chose que des lettres a-z sont
+// renvoyés tels quels.
+func stem(word string) string {
+	if len(word) <= 2 {
+		return word
+	}
+	for i := 0; i < len(word); i++ {
+		if word[i] < 'a' || word[i] > 'z' {
+			return word
+		}
+	}
+
+	p := porter{b: []byte(word), k: len(word) - 1}
+	p.step1ab()
+	if p.k > 0 {
+		p.step1c()
+		p.step2()
+		p.step3()
+		p.step4()
+		p.step5()
+	}
+	return string(p.b[:p.k+1])
+}
+
+// porter garde l'état du stemmer : b[0..k] est le mot courant et j marque la
+// fin de la racine après un appel réussi à ends.
+type porter struct {
+	b    []byte
+	k, j int
+}
+
+// cons indique si b[i] est une consonne
+func (p *porter) cons(i int) bool {
+	switch p.b[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		return i == 0 || !p.cons(i-1)
+	}
+	return true
+}
+
+// m compte les séquences voyelles-consonnes de la racine b[0..j]
+func (p *porter) m() int {
+	n, i := 0, 0
+	for {
+		if i > p.j {
+			return n
+		}
+		if !p.cons(i) {
+			break
+		}
+		i++
+	}
+	i++
+	for {
+		for {
+			if i > p.j {
+				return n
+			}
+			if p.cons(i) {
+				break
+			}
+			i++
+		}
+		i++
+		n++
+		for {
+			if i > p.j {
+				return n
+			}
+			if !p.cons(i) {
+				break
+			}
+			i++
+		}
+		i++
+	}
+}
+
+// vowelInStem indique si la racine b[0..j] contient une voyelle
+func (p *porter) vowelInStem() bool {
+	for i := 0; i <= p.j; i++ {
+		if !p.cons(i) {
+			return true
+		}
+	}
+	return false
+}
+
+// doublec indique si b[i-1..i] est une double consonne
+func (p *porter) doublec(i int) bool {
+	return i >= 1 && p.b[i] == p.b[i-1] && p.cons(i)
+}
+
+// cvc indique si b[i-2..i] suit le motif consonne-voyelle-consonne, la
+// dernière consonne n'étant pas w, x ou y
+func (p *porter) cvc(i int) bool {
+	if i < 2 || !p.cons(i) || p.cons(i-1) || !p.cons(i-2) {
+		return false
+	}
+	switch p.b[i] {
+	case 'w', 'x', 'y':
+		return false
+	}
+	return true
+}
+
+// ends indique si b[0..k] se termine par s et positionne j en conséquence
+func (p *porter) ends(s string) bool {
+	l := len(s)
+	if l > p.k+1 || string(p.b[p.k-l+1:p.k+1]) != s {
+		return false
+	}
+	p.j = p.k - l
+	return true
+}
+
+// setTo remplace b[j+1..k] par s
+func (p *porter) setTo(s string) {
+	p.b = append(p.b[:p.j+1], s...)
+	p.k = p.j + len(s)
+}
+
+// replaceSuffix applique le premier remplacement dont le suffixe correspond,
+// si la racine restante a une mesure positive
+func (p *porter) replaceSuffix(pairs [][2]string) {
+	for _, pair := range pairs {
+		if p.ends(pair[0]) {
+			if p.m() > 0 {
+				p.setTo(pair[1])
+			}
+			return
+		}
+	}
+}
+
+// step1ab retire les pluriels et les terminaisons -ed ou -ing
+func (p *porter) step1ab() {
+	if p.b[p.k] == 's' {
+		switch {
+		case p.ends("sses"):
+			p.k -= 2
+		case p.ends("ies"):
+			p.setTo("i")
+		case p.b[p.k-1] != 's':
+			p.k--
+		}
+	}
+
+	if p.ends("eed") {
+		if p.m() > 0 {
+			p.k--
+		}
+		return
+	}
+	if (p.ends("ed") || p.ends("ing")) && p.vowelInStem() {
+		p.k = p.j
+		switch {
+		case p.ends("at"):
+			p.setTo("ate")
+		case p.ends("bl"):
+			p.setTo("ble")
+		case p.ends("iz"):
+			p.setTo("ize")
+		case p.doublec(p.k):
+			switch p.b[p.k] {
+			case 'l', 's', 'z':
+			default:
+				p.k--
+			}
+		case p.m() == 1 && p.cvc(p.k):
+			p.setTo("e")
+		}
+	}
+}
+
+// step1c remplace le y final par i quand la racine contient une voyelle
+func (p *porter) step1c() {
+	if p.ends("y") && p.vowelInStem() {
+		p.b[p.k] = 'i'
+	}
+}
+
+// step2 réduit les doubles suffixes (-ization, -ational...) à un seul
+func (p *porter) step2() {
+	switch p.b[p.k-1] {
+	case 'a':
+		p.replaceSuffix([][2]string{{"ational", "ate"}, {"tional", "tion"}})
+	case 'c':
+		p.replaceSuffix([][2]string{{"enci", "ence"}, {"anci", "ance"}})
+	case 'e':
+		p.replaceSuffix([][2]string{{"izer", "ize"}})
+	case 'l':
+		p.replaceSuffix([][2]string{{"bli", "ble"}, {"alli", "al"}, {"entli", "ent"}, {"eli", "e"}, {"ousli", "ous"}})
+	case 'o':
+		p.replaceSuffix([][2]string{{"ization", "ize"}, {"ation", "ate"}, {"ator", "ate"}})
+	case 's':
+		p.replaceSuffix([][2]string{{"alism", "al"}, {"iveness", "ive"}, {"fulness", "ful"}, {"ousness", "ous"}})
+	case 't':
+		p.replaceSuffix([][2]string{{"aliti", "al"}, {"iviti", "ive"}, {"biliti", "ble"}})
+	case 'g':
+		p.replaceSuffix([][2]string{{"logi", "log"}})
+	}
+}
+
+// step3 traite les suffixes -ic-, -full, -ness...
+func (p *porter) step3() {
+	switch p.b[p.k] {
+	case 'e':
+		p.replaceSuffix([][2]string{{"icate", "ic"}, {"ative", ""}, {"alize", "al"}})
+	case 'i':
+		p.replaceSuffix([][2]string{{"iciti", "ic"}})
+	case 'l':
+		p.replaceSuffix([][2]string{{"ical", "ic"}, {"ful", ""}})
+	case 's':
+		p.replaceSuffix([][2]string{{"ness", ""}})
+	}
+}
+
+// step4Suffixes liste les suffixes retirés par step4, par avant-dernière lettre
+var step4Suffixes = map[byte][]string{
+	'a': {"al"},
+	'c': {"ance", "ence"},
+	'e': {"er"},
+	'i': {"ic"},
+	'l': {"able", "ible"},
+	'n': {"ant", "ement", "ment", "ent"},
+	's': {"ism"},
+	't': {"ate", "iti"},
+	'u': {"ous"},
+	'v': {"ive"},
+	'z': {"ize"},
+}
+
+// step4 retire -ant, -ence... quand la racine a une mesure supérieure à 1
+func (p *porter) step4() {
+	found := false
+	if p.b[p.k-1] == 'o' {
+		// -ion n'est retiré qu'après s ou t
+		found = (p.ends("ion") && p.j >= 0 && (p.b[p.j] == 's' || p.b[p.j] == 't')) || p.ends("ou")
+	} else {
+		for _, suffix := range step4Suffixes[p.b[p.k-1]] {
+			if p.ends(suffix) {
+				found = true
+				break
+			}
+		}
+	}
+	if found && p.m() > 1 {
+		p.k = p.j
+	}
+}
+
+// step5 retire le e final et réduit -ll à -l quand la mesure le permet
+func (p *porter) step5() {
+	p.j = p.k
+	if p.b[p.k] == 'e' {
+		a := p.m()
+		if a > 1 || a == 1 && !p.cvc(p.k-1) {
+			p.k--
+		}
+	}
+	if p.b[p.k] == 'l' && p.doublec(p.k) && p.m() > 1 {
+		p.k--
+	}
+}
@@ -0,0 +1,309 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"unicode"
+)
+
+// Paramètres BM25 et poids du nom par rapport au texte de la carte
+const (
+	bm25K1       = 1.2
+	bm25B        = 0.75
+	nameBoost    = 2.0
+	maxExpansion = 50
+)
+
+// cardText est l'index plein texte courant, reconstruit à chaque mise à jour
+// du miroir. Il reste nil tant qu'aucune carte n'a été chargée.
+var cardText atomic.Pointer[textIndex]
+
+// textIndex est un index inversé positionnel sur le nom et le texte des cartes
+type textIndex struct {
+	cards []Card
+	name  fieldIndex
+	desc  fieldIndex
+}
+
+// fieldIndex indexe un champ : pour chaque terme, la liste triée des cartes
+// qui le contiennent avec les positions des occurrences
+type fieldIndex struct {
+	postings map[string][]posting
+	terms    []string // termes triés, pour les requêtes par préfixe
+	lengths  []int
+	avgLen   float64
+}
+
+type posting struct {
+	doc       int
+	positions []int
+}
+
+// textHit est une carte trouvée avec son score de pertinence
+type textHit struct {
+	Card
+	Score float64 `json:"score"`
+}
+
+func buildTextIndex(cards []Card) *textIndex {
+	idx := &textIndex{cards: cards}
+	idx.name = buildFieldIndex(cards, func(c Card) string { return c.Name })
+	idx.desc = buildFieldIndex(cards, func(c Card) string { return c.Desc })
+	return idx
+}
+
+func buildFieldIndex(cards []Card, field func(Card) string) fieldIndex {
+	f := fieldIndex{postings: make(map[string][]posting), lengths: make([]int, len(cards))}
+	total := 0
+	for doc, c := range cards {
+		tokens := tokenize(field(c))
+		f.lengths[doc] = len(tokens)
+		total += len(tokens)
+		for pos, tok := range tokens {
+			term := stem(tok)
+			list := f.postings[term]
+			if n := len(list); n > 0 && list[n-1].doc == doc {
+				list[n-1].positions = append(list[n-1].positions, pos)
+			} else {
+				list = append(list, posting{doc: doc, positions: []int{pos}})
+			}
+			f.postings[term] = list
+		}
+	}
+	f.avgLen = 1
+	if total > 0 {
+		f.avgLen = float64(total) / float64(len(cards))
+	}
+	f.terms = make([]string, 0, len(f.postings))
+	for term := range f.postings {
+		f.terms = append(f.terms, term)
+	}
+	sort.Strings(f.terms)
+	return f
+}
+
+// tokenize découpe un texte en mots en minuscules. Les apostrophes internes
+// sont retirées et le possessif 's supprimé ("opponent's" → "opponent").
+func tokenize(text string) []string {
+	var tokens []string
+	var cur strings.Builder
+	flush := func() {
+		tok := strings.TrimSuffix(cur.String(), "'s")
+		tok = strings.ReplaceAll(tok, "'", "")
+		if tok != "" {
+			tokens = append(tokens, tok)
+		}
+		cur.Reset()
+	}
+	for _, r := range text {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			cur.WriteRune(unicode.ToLower(r))
+		case (r == '\'' || r == '’') && cur.Len() > 0:
+			cur.WriteByte('\'')
+		default:
+			flush()
+		}
+	}
+	flush()
+	return tokens
+}
+
+type clauseKind int
+
+const (
+	termClause clauseKind = iota
+	prefixClause
+	phraseClause
+)
+
+// textClause est un élément de requête : un terme, un préfixe (banish*) ou
+// une phrase entre guillemets. Les clauses précédées de - excluent des cartes.
+type textClause struct {
+	kind   clauseKind
+	terms  []string
+	negate bool
+}
+
+// parseTextQuery analyse une requête du type
+// `"negate the activation" banish* -trap`
+func parseTextQuery(query string) []textClause {
+	var clauses []textClause
+	rest := strings.TrimSpace(query)
+	for rest != "" {
+		negate := false
+		if rest[0] == '-' {
+			negate = true
+			rest = rest[1:]
+		}
+
+		var raw string
+		quoted := false
+		if strings.HasPrefix(rest, `"`) {
+			quoted = true
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				raw, rest = rest[1:], ""
+			} else {
+				raw, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else if end := strings.IndexFunc(rest, unicode.IsSpace); end >= 0 {
+			raw, rest = rest[:end], rest[end:]
+		} else {
+			raw, rest = rest, ""
+		}
+		rest = strings.TrimSpace(rest)
+
+		prefix := !quoted && strings.HasSuffix(raw, "*")
+		tokens := tokenize(raw)
+		switch {
+		case len(tokens) == 0:
+			continue
+		case len(tokens) == 1 && prefix:
+			clauses = append(clauses, textClause{kind: prefixClause, terms: tokens, negate: negate})
+		case len(tokens) == 1:
+			clauses = append(clauses, textClause{kind: termClause, terms: []string{stem(tokens[0])}, negate: negate})
+		default:
+			terms := make([]string, len(tokens))
+			for i, tok := range tokens {
+				terms[i] = stem(tok)
+			}
+			clauses = append(clauses, textClause{kind: phraseClause, terms: terms, negate: negate})
+		}
+	}
+	return clauses
+}
+
+// search retourne les cartes satisfaisant toutes les clauses, triées par
+// score BM25 décroissant
+func (idx *textIndex) search(query string) []textHit {
+	clauses := parseTextQuery(query)
+
+	var scores map[int]float64
+	excluded := make(map[int]bool)
+	for _, clause := range clauses {
+		matches := idx.name.match(clause, nameBoost)
+		for doc, s := range idx.desc.match(clause, 1) {
+			matches[doc] += s
+		}
+
+		if clause.negate {
+			for doc := range matches {
+				excluded[doc] = true
+			}
+			continue
+		}
+		if scores == nil {
+			scores = matches
+			continue
+		}
+		for doc := range scores {
+			if s, ok := matches[doc]; ok {
+				scores[doc] += s
+			} else {
+				delete(scores, doc)
+			}
+		}
+	}
+
+	hits := make([]textHit, 0, len(scores))
+	for doc, s := range scores {
+		if !excluded[doc] {
+			hits = append(hits, textHit{Card: idx.cards[doc], Score: roundScore(s)})
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		if hits[i].Name != hits[j].Name {
+			return hits[i].Name < hits[j].Name
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	return hits
+}
+
+// match calcule le score BM25 pondéré de la clause pour chaque carte du champ
+func (f *fieldIndex) match(clause textClause, weight float64) map[int]float64 {
+	scores := make(map[int]float64)
+	add := func(freqs map[int]int) {
+		idf := f.idf(len(freqs))
+		for doc, tf := range freqs {
+			scores[doc] += weight * idf * f.bm25(tf, doc)
+		}
+	}
+
+	switch clause.kind {
+	case termClause:
+		add(f.termFreqs(clause.terms[0]))
+	case prefixClause:
+		// Les termes indexés sont racinisés : "activation*" doit trouver "activ"
+		prefix := commonPrefix(clause.terms[0], stem(clause.terms[0]))
+		i := sort.SearchStrings(f.terms, prefix)
+		for n := 0; i < len(f.terms) && n < maxExpansion && strings.HasPrefix(f.terms[i], prefix); i, n = i+1, n+1 {
+			add(f.termFreqs(f.terms[i]))
+		}
+	case phraseClause:
+		add(f.phraseFreqs(clause.terms))
+	}
+	return scores
+}
+
+func (f *fieldIndex) termFreqs(term string) map[int]int {
+	freqs := make(map[int]int)
+	for _, p := range f.postings[term] {
+		freqs[p.doc] = len(p.positions)
+	}
+	return freqs
+}
+
+// phraseFreqs compte les occurrences consécutives des termes dans chaque carte
+func (f *fieldIndex) phraseFreqs(terms []string) map[int]int {
+	lists := make([]map[int][]int, len(terms))
+	for i, term := range terms {
+		lists[i] = make(map[int][]int)
+		for _, p := range f.postings[term] {
+			lists[i][p.doc] = p.positions
+		}
+	}
+
+	freqs := make(map[int]int)
+	for doc, starts := range lists[0] {
+	next:
+		for _, start := range starts {
+			for i := 1; i < len(terms); i++ {
+				positions, ok := lists[i][doc]
+				if !ok {
+					continue next
+				}
+				k := sort.SearchInts(positions, start+i)
+				if k == len(positions) || positions[k] != start+i {
+					continue next
+				}
+			}
+			freqs[doc]++
+		}
+	}
+	return freqs
+}
+
+func (f *fieldIndex) idf(df int) float64 {
+	n := float64(len(f.lengths))
+	return math.Log(1 + (n-float64(df)+0.5)/(float64(df)+0.5))
+}
+
+func (f *fieldIndex) bm25(tf, doc int) float64 {
+	norm := 1 - bm25B + bm25B*float64(f.lengths[doc])/f.avgLen
+	return float64(tf) * (bm25K1 + 1) / (float64(tf) + bm25K1*norm)
+}
+
+func commonPrefix(a, b string) string {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return a[:n]
+}
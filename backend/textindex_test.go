@@ -0,0 +1,90 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStem(t *testing.T) {
+	// Exemples de l'article de Porter et de son vocabulaire de référence
+	tests := []struct{ word, want string }{
+		{"caresses", "caress"},
+		{"ponies", "poni"},
+		{"ties", "ti"},
+		{"cats", "cat"},
+		{"feed", "feed"},
+		{"agreed", "agre"},
+		{"plastered", "plaster"},
+		{"bled", "bled"},
+		{"motoring", "motor"},
+		{"sing", "sing"},
+		{"conflated", "conflat"},
+		{"troubled", "troubl"},
+		{"sized", "size"},
+		{"hopping", "hop"},
+		{"falling", "fall"},
+		{"hissing", "hiss"},
+		{"filing", "file"},
+		{"happy", "happi"},
+		{"sky", "sky"},
+		{"relational", "relat"},
+		{"conditional", "condit"},
+		{"generalization", "gener"},
+		{"oscillators", "oscil"},
+		{"hopeful", "hope"},
+		{"goodness", "good"},
+		{"allowance", "allow"},
+		{"adjustment", "adjust"},
+		{"controll", "control"},
+		{"activation", "activ"},
+		{"activate", "activ"},
+		{"banished", "banish"},
+		// Mots courts ou hors a-z inchangés
+		{"is", "is"},
+		{"2400", "2400"},
+		{"pokémon", "pokémon"},
+	}
+	for _, tt := range tests {
+		if got := stem(tt.word); got != tt.want {
+			t.Errorf("stem(%q) = %q, want %q", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestTextIndexSearch(t *testing.T) {
+	idx := buildTextIndex([]Card{
+		{ID: 1, Name: "Solemn Judgment", Type: "Trap Card", Desc: "When a monster would be Summoned, OR a Spell/Trap Card is activated: Pay half your LP; negate the Summon or activation, and if you do, destroy that card."},
+		{ID: 2, Name: "Called by the Grave", Type: "Spell Card", Desc: "Target 1 monster in your opponent's GY; banish it, and if you do, until the end of the next turn, negate its effects."},
+		{ID: 3, Name: "Crossout Designator", Type: "Spell Card", Desc: "Declare 1 card name; banish 1 of that declared card from your Main Deck."},
+		{ID: 4, Name: "Macro Cosmos", Type: "Trap Card", Desc: "Any card sent to the GY is banished instead."},
+		{ID: 5, Name: "Negate Wall", Type: "Trap Card", Desc: "The activation of this card cannot be negated."},
+	})
+	ids := func(hits []textHit) []int {
+		out := []int{}
+		for _, h := range hits {
+			out = append(out, h.ID)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []int
+	}{
+		{"terme racinisé", "activated", []int{5, 1}},
+		{"phrase contiguë", `"negate the activation"`, []int{}},
+		{"phrase", `"negate the summon"`, []int{1}},
+		{"préfixe", "banish*", []int{4, 3, 2}},
+		{"exclusion", "banish* -gy", []int{3}},
+		{"plusieurs termes", "banish declared", []int{3}},
+		{"nom pondéré", "negate", []int{5, 2, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ids(idx.search(tt.query)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("search(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxTournamentImport borne la taille d'un fichier d'import de tournois
+const maxTournamentImport = 8 << 20
+
+// TournamentImport résume un import de tournois
+type TournamentImport struct {
+	Created     int          `json:"created"`
+	Updated     int          `json:"updated"`
+	Tournaments []Tournament `json:"tournaments"`
+}
+
+// handleTournaments liste les tournois (GET, filtrables par format et par
+// dates from/to) ou en enregistre un (POST, en-tête X-User requis)
+func handleTournaments(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		page, err := parsePagination(query)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, APIResponse{Error: err.Error(), Status: "error"})
+			return
+		}
+		format := ""
+		if f := query.Get("format"); f != "" {
+			var ok bool
+			if format, ok = normalizeFormat(f); !ok {
+				writeJSON(w, http.StatusBadRequest, APIResponse{Error: "Format inconnu: " + f, Status: "error"})
+				return
+			}
+		}
+		from, to := query.Get("from"), query.Get("to")
+		for _, d := range []string{from, to} {
+			if _, err := time.Parse("2006-01-02", d); d != "" && err != nil {
+				writeJSON(w, http.StatusBadRequest, APIResponse{Error: "Date invalide (AAAA-MM-JJ): " + d, Status: "error"})
+				return
+			}
+		}
+		list := []Tournament{}
+		for _, t := range tournaments.list() {
+			if (format == "" || t.Format == format) && (from == "" || t.Date >= from) && (to == "" || t.Date <= to) {
+				list = append(list, t)
+			}
+		}
+		list = paginate(list, &page)
+		writeJSON(w, http.StatusOK, APIResponse{Data: list, Status: "success", Pagination: &page})
+
+	case http.MethodPost:
+		user := currentUser(r)
+		if user == "" {
+			writeJSON(w, http.StatusUnauthorized, APIResponse{Error: "En-tête 'X-User' requis", Status: "error"})
+			return
+		}
+		var t Tournament
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxDeckBody)).Decode(&t); err != nil {
+			writeJSON(w, http.StatusBadRequest, APIResponse{Error: "Tournoi invalide: " + err.Error(), Status: "error"})
+			return
+		}
+		t.ID = ""
+		if err := t.normalize(); err != nil {
+			writeJSON(w, http.StatusBadRequest, APIResponse{Error: "Tournoi invalide: " + err.Error(), Status: "error"})
+			return
+		}
+		saved, created, err := tournaments.put(user, []Tournament{t})
+		if err != nil {
+			writeTournamentError(w, err)
+			return
+		}
+		status := http.StatusOK
+		if created[0] {
+			status = http.StatusCreated
+		}
+		writeJSON(w, status, APIResponse{Data: saved[0], Status: "success"})
+
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeJSON(w, http.StatusMethodNotAllowed, APIResponse{Error: "Méthode non autorisée", Status: "error"})
+	}
+}
+
+// handleTournament lit (GET), remplace (PUT) ou supprime (DELETE) le tournoi
+// /api/tournaments/{id}. Seul le propriétaire peut le modifier ou le supprimer.
+func handleTournament(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	id := strings.TrimPrefix(r.URL.Path, "/api/tournaments/")
+	t, ok := tournaments.get(id)
+	if !ok {
+		writeJSON(w, http.StatusNotFound, APIResponse{Error: "Tournoi non trouvé", Status: "error"})
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, APIResponse{Data: t, Status: "success"})
+
+	case http.MethodPut:
+		user := currentUser(r)
+		if user == "" {
+			writeJSON(w, http.StatusUnauthorized, APIResponse{Error: "En-tête 'X-User' requis", Status: "error"})
+			return
+		}
+		if t.Owner != user {
+			writeTournamentError(w, errTournamentForbidden)
+			return
+		}
+		var updated Tournament
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxDeckBody)).Decode(&updated); err != nil {
+			writeJSON(w, http.StatusBadRequest, APIResponse{Error: "Tournoi invalide: " + err.Error(), Status: "error"})
+			return
+		}
+		updated.ID = id
+		if err := updated.normalize(); err != nil {
+			writeJSON(w, http.StatusBadRequest, APIResponse{Error: "Tournoi invalide: " + err.Error(), Status: "error"})
+			return
+		}
+		saved, _, err := tournaments.put(user, []Tournament{updated})
+		if err != nil {
+			writeTournamentError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, APIResponse{Data: saved[0], Status: "success"})
+
+	case http.MethodDelete:
+		user := currentUser(r)
+		if user == "" {
+			writeJSON(w, http.StatusUnauthorized, APIResponse{Error: "En-tête 'X-User' requis", Status: "error"})
+			return
+		}
+		if err := tournaments.delete(id, user); err != nil {
+			writeTournamentError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, APIResponse{Data: id, Status: "success"})
+
+	default:
+		w.Header().Set("Allow", "GET, PUT, DELETE")
+		writeJSON(w, http.StatusMethodNotAllowed, APIResponse{Error: "Méthode non autorisée", Status: "error"})
+	}
+}
+
+// importTournaments enregistre en POST un lot de tournois, en JSON (tableau
+// de tournois) ou en CSV (une ligne par classement). Un tournoi déjà connu
+// (même nom, même date) est remplacé. Le lot est refusé entièrement si une
+// seule entrée est invalide ou appartient à un autre utilisateur.
+func importTournaments(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSON(w, http.StatusMethodNotAllowed, APIResponse{Error: "Méthode non autorisée", Status: "error"})
+		return
+	}
+	user := currentUser(r)
+	if user == "" {
+		writeJSON(w, http.StatusUnauthorized, APIResponse{Error: "En-tête 'X-User' requis", Status: "error"})
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxTournamentImport))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, APIResponse{Error: "Lecture du fichier impossible: " + err.Error(), Status: "error"})
+		return
+	}
+
+	var list []Tournament
+	trimmed := bytes.TrimSpace(body)
+	switch {
+	case len(trimmed) > 0 && trimmed[0] == '[':
+		err = json.Unmarshal(trimmed, &list)
+	case len(trimmed) > 0 && trimmed[0] == '{':
+		var t Tournament
+		err = json.Unmarshal(trimmed, &t)
+		list = []Tournament{t}
+	default:
+		list, err = parseTournamentCSV(bytes.NewReader(trimmed))
+	}
+	if err == nil && len(list) == 0 {
+		err = errors.New("aucun tournoi")
+	}
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, APIResponse{Error: "Fichier invalide: " + err.Error(), Status: "error"})
+		return
+	}
+	for i := range list {
+		list[i].ID = ""
+		if err := list[i].normalize(); err != nil {
+			writeJSON(w, http.StatusBadRequest, APIResponse{Error: "Tournoi invalide: " + err.Error(), Status: "error"})
+			return
+		}
+	}
+
+	saved, created, err := tournaments.put(user, list)
+	if err != nil {
+		writeTournamentError(w, err)
+		return
+	}
+	result := TournamentImport{Tournaments: saved}
+	for _, c := range created {
+		if c {
+			result.Created++
+		} else {
+			result.Updated++
+		}
+	}
+	writeJSON(w, http.StatusOK, APIResponse{Data: result, Status: "success"})
+}
+
+// writeTournamentError traduit une erreur du dépôt de tournois en réponse HTTP
+func writeTournamentError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errTournamentNotFound):
+		writeJSON(w, http.StatusNotFound, APIResponse{Error: "Tournoi non trouvé", Status: "error"})
+	case errors.Is(err, errTournamentForbidden):
+		writeJSON(w, http.StatusForbidden, APIResponse{Error: "Ce tournoi appartient à un autre utilisateur", Status: "error"})
+	default:
+		writeJSON(w, http.StatusInternalServerError, APIResponse{Error: "Enregistrement du tournoi impossible: " + err.Error(), Status: "error"})
+	}
+}
+
+// parseTournamentCSV lit un CSV avec en-tête, une ligne par classement.
+// Colonnes requises : tournament, date, format, placement, player ;
+// facultatives : location, players, tier, top_cut, deck_id, archetype. Les
+// lignes d'un même tournoi (nom et date) sont regroupées.
+func parseTournamentCSV(r io.Reader) ([]Tournament, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("en-tête CSV illisible: %w", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"tournament", "date", "format", "placement", "player"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("colonne %q manquante", required)
+		}
+	}
+
+	var list []Tournament
+	byKey := make(map[string]int)
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		number := func(name string) (int, error) {
+			v := field(name)
+			if v == "" {
+				return 0, nil
+			}
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return 0, fmt.Errorf("ligne %d: %s invalide: %q", line, name, v)
+			}
+			return n, nil
+		}
+
+		key := strings.ToLower(field("tournament")) + "|" + field("date")
+		i, ok := byKey[key]
+		if !ok {
+			t := Tournament{Name: field("tournament"), Date: field("date"), Format: field("format"), Location: field("location")}
+			for name, dst := range map[string]*int{"players": &t.Players, "tier": &t.Tier, "top_cut": &t.TopCut} {
+				if *dst, err = number(name); err != nil {
+					return nil, err
+				}
+			}
+			i = len(list)
+			byKey[key] = i
+			list = append(list, t)
+		}
+		placement, err := number("placement")
+		if err != nil {
+			return nil, err
+		}
+		list[i].Standings = append(list[i].Standings, Standing{
+			Placement: placement,
+			Player:    field("player"),
+			DeckID:    field("deck_id"),
+			Archetype: field("archetype"),
+		})
+	}
+	return list, nil
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// useTestStores remplace les dépôts de decks et de tournois par des dépôts
+// vides le temps du test
+func useTestStores(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	previousDecks, previousTournaments := decks, tournaments
+	decks = newDeckStore(filepath.Join(dir, "decks.json"))
+	tournaments = newTournamentStore(filepath.Join(dir, "tournaments.json"))
+	t.Cleanup(func() { decks, tournaments = previousDecks, previousTournaments })
+}
+
+func TestParseTournamentCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []Tournament
+		wantErr bool
+	}{
+		{
+			name: "lignes regroupées par tournoi",
+			input: "Tournament,Date,Format,Placement,Player,Players,Tier,Archetype\n" +
+				"YCS Lille,2026-03-01,TCG,1,Ana,512,1,Snake-Eye\n" +
+				"Locals,2026-03-02,tcg,1,Bo,,,\n" +
+				"ycs lille,2026-03-01,TCG,2,Cy,512,1,Yubel\n",
+			want: []Tournament{
+				{Name: "YCS Lille", Date: "2026-03-01", Format: "TCG", Players: 512, Tier: 1, Standings: []Standing{
+					{Placement: 1, Player: "Ana", Archetype: "Snake-Eye"},
+					{Placement: 2, Player: "Cy", Archetype: "Yubel"},
+				}},
+				{Name: "Locals", Date: "2026-03-02", Format: "tcg", Standings: []Standing{{Placement: 1, Player: "Bo"}}},
+			},
+		},
+		{
+			name:  "colonnes dans le désordre, espaces",
+			input: "player, placement, format, date, tournament, top_cut\n Ana , 1, OCG, 2026-03-01, Locals, 8\n",
+			want: []Tournament{
+				{Name: "Locals", Date: "2026-03-01", Format: "OCG", TopCut: 8, Standings: []Standing{{Placement: 1, Player: "Ana"}}},
+			},
+		},
+		{name: "fichier vide", input: "", wantErr: true},
+		{name: "colonne manquante", input: "tournament,date,format,player\nLocals,2026-03-01,TCG,Ana\n", wantErr: true},
+		{name: "place invalide", input: "tournament,date,format,placement,player\nLocals,2026-03-01,TCG,premier,Ana\n", wantErr: true},
+		{name: "nombre de joueurs invalide", input: "tournament,date,format,placement,player,players\nLocals,2026-03-01,TCG,1,Ana,beaucoup\n", wantErr: true},
+		{name: "ligne incomplète", input: "tournament,date,format,placement,player\nLocals,2026-03-01,TCG\n", wantErr: true},
+		{name: "guillemet non fermé", input: "tournament,date,format,placement,player\n\"Locals,2026-03-01,TCG,1,Ana\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTournamentCSV(strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("erreur = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseTournamentCSV() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTournamentNormalize(t *testing.T) {
+	useTestStores(t)
+	valid := func(standings ...Standing) Tournament {
+		return Tournament{Name: "Locals", Date: "2026-03-01", Format: "tcg", Standings: standings}
+	}
+	tests := []struct {
+		name    string
+		t       Tournament
+		wantErr bool
+	}{
+		{name: "valide", t: valid(Standing{Placement: 2, Player: "Bo"}, Standing{Placement: 1, Player: "Ana"})},
+		{name: "ex æquo", t: valid(Standing{Placement: 1, Player: "Ana"}, Standing{Placement: 3, Player: "Bo"}, Standing{Placement: 3, Player: "Cy"}, Standing{Placement: 5, Player: "Di"})},
+		{name: "classement partiel", t: valid(Standing{Placement: 1, Player: "Ana"}, Standing{Placement: 9, Player: "Bo"})},
+		{name: "nom manquant", t: Tournament{Date: "2026-03-01", Format: "TCG"}, wantErr: true},
+		{name: "date invalide", t: Tournament{Name: "Locals", Date: "01/03/2026", Format: "TCG"}, wantErr: true},
+		{name: "format inconnu", t: Tournament{Name: "Locals", Date: "2026-03-01", Format: "Speed Duel"}, wantErr: true},
+		{name: "tier invalide", t: Tournament{Name: "Locals", Date: "2026-03-01", Format: "TCG", Tier: 4}, wantErr: true},
+		{name: "joueurs négatifs", t: Tournament{Name: "Locals", Date: "2026-03-01", Format: "TCG", Players: -1}, wantErr: true},
+		{name: "place nulle", t: valid(Standing{Placement: 0, Player: "Ana"}), wantErr: true},
+		{name: "joueur manquant", t: valid(Standing{Placement: 1, Player: " "}), wantErr: true},
+		{name: "deck inconnu", t: valid(Standing{Placement: 1, Player: "Ana", DeckID: "inconnu"}), wantErr: true},
+		{name: "joueur classé deux fois", t: valid(Standing{Placement: 1, Player: "Ana"}, Standing{Placement: 2, Player: "ana"}), wantErr: true},
+		{name: "place déjà occupée", t: valid(Standing{Placement: 1, Player: "Ana"}, Standing{Placement: 1, Player: "Bo"}, Standing{Placement: 2, Player: "Cy"}), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.t.normalize()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalize() erreur = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	tournament := valid(Standing{Placement: 2, Player: "Bo"}, Standing{Placement: 1, Player: "Ana"})
+	if err := tournament.normalize(); err != nil {
+		t.Fatal(err)
+	}
+	if tournament.Format != "TCG" || tournament.Tier != tierLocal || tournament.Players != 2 || tournament.Standings[0].Player != "Ana" {
+		t.Errorf("valeurs par défaut non appliquées: %+v", tournament)
+	}
+}
+
+func TestImportTournaments(t *testing.T) {
+	useTestStores(t)
+	post := func(user, body string) (int, APIResponse) {
+		r := httptest.NewRequest(http.MethodPost, "/api/tournaments/import", strings.NewReader(body))
+		if user != "" {
+			r.Header.Set("X-User", user)
+		}
+		rec := httptest.NewRecorder()
+		importTournaments(rec, r)
+		var resp APIResponse
+		json.NewDecoder(rec.Body).Decode(&resp)
+		return rec.Code, resp
+	}
+
+	csv := "tournament,date,format,placement,player\nLocals,2026-03-01,TCG,1,Ana\nLocals,2026-03-01,TCG,2,Bo\n"
+	tests := []struct {
+		name string
+		user string
+		body string
+		want int
+	}{
+		{"sans X-User", "", csv, http.StatusUnauthorized},
+		{"CSV", "ana", csv, http.StatusOK},
+		{"objet JSON", "ana", `{"name":"Regional","date":"2026-03-08","format":"TCG","tier":2,"standings":[{"placement":1,"player":"Cy"}]}`, http.StatusOK},
+		{"tableau JSON", "ana", `[{"name":"YCS","date":"2026-03-15","format":"OCG","standings":[]}]`, http.StatusOK},
+		{"fichier vide", "ana", "  ", http.StatusBadRequest},
+		{"tableau vide", "ana", "[]", http.StatusBadRequest},
+		{"JSON invalide", "ana", `[{"name":`, http.StatusBadRequest},
+		{"colonne manquante", "ana", "tournament,date,placement,player\nLocals,2026-03-01,1,Ana\n", http.StatusBadRequest},
+		{"ligne invalide", "ana", "tournament,date,format,placement,player\nLocals,2026-03-01,TCG,1,Ana\nLocals,2026-03-01,TCG,x,Bo\n", http.StatusBadRequest},
+		{"place en double", "ana", "tournament,date,format,placement,player\nCup,2026-03-01,TCG,1,Ana\nCup,2026-03-01,TCG,1,Bo\nCup,2026-03-01,TCG,2,Cy\n", http.StatusBadRequest},
+		{"ligne en double", "ana", "tournament,date,format,placement,player\nCup,2026-03-01,TCG,1,Ana\nCup,2026-03-01,TCG,1,Ana\n", http.StatusBadRequest},
+		{"tournoi d'un autre utilisateur", "bo", csv, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		if code, resp := post(tt.user, tt.body); code != tt.want {
+			t.Errorf("%s: statut %d (%s), want %d", tt.name, code, resp.Error, tt.want)
+		}
+	}
+
+	// Le lot refusé n'a rien enregistré ; le CSV réimporté remplace Locals
+	if n := len(tournaments.list()); n != 3 {
+		t.Errorf("%d tournois enregistrés, want 3", n)
+	}
+	code, resp := post("ana", csv)
+	data, _ := json.Marshal(resp.Data)
+	var result TournamentImport
+	json.Unmarshal(data, &result)
+	if code != http.StatusOK || result.Created != 0 || result.Updated != 1 {
+		t.Errorf("réimport: statut %d, %+v", code, result)
+	}
+}
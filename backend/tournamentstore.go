@@ -0,0 +1,299 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Niveaux de tournoi, du plus prestigieux au plus modeste
+const (
+	tierPremier  = 1 // YCS, WCQ, championnats continentaux et mondiaux
+	tierRegional = 2 // régionaux et équivalents
+	tierLocal    = 3 // tournois de boutique
+)
+
+var (
+	errTournamentNotFound  = errors.New("tournoi non trouvé")
+	errTournamentForbidden = errors.New("ce tournoi appartient à un autre utilisateur")
+)
+
+// Tournament est un tournoi et son classement
+type Tournament struct {
+	ID        string     `json:"id"`
+	Owner     string     `json:"owner,omitempty"` // utilisateur (X-User) qui l'a enregistré
+	Name      string     `json:"name"`
+	Date      string     `json:"date"`   // AAAA-MM-JJ
+	Format    string     `json:"format"` // nom canonique du format de banlist
+	Location  string     `json:"location,omitempty"`
+	Players   int        `json:"players"`
+	Tier      int        `json:"tier"`    // 1 premier, 2 régional, 3 local
+	TopCut    int        `json:"top_cut"` // joueurs qualifiés en phase finale, 0 si inconnu
+	Standings []Standing `json:"standings"`
+}
+
+// Standing est la place d'un joueur, éventuellement liée à un deck enregistré
+type Standing struct {
+	Placement int    `json:"placement"` // 1 pour le vainqueur
+	Player    string `json:"player"`
+	DeckID    string `json:"deck_id,omitempty"`
+	Archetype string `json:"archetype,omitempty"`
+}
+
+// tournaments est le dépôt de tournois utilisé par les handlers
+var tournaments *tournamentStore
+
+// tournamentStore enregistre les tournois dans un fichier JSON, comme
+// deckStore pour les decks
+type tournamentStore struct {
+	path string
+
+	mu    sync.RWMutex
+	items map[string]Tournament
+}
+
+func newTournamentStore(path string) *tournamentStore {
+	return &tournamentStore{path: path, items: make(map[string]Tournament)}
+}
+
+// load lit le fichier des tournois, s'il existe
+func (s *tournamentStore) load() error {
+	var list []Tournament
+	if err := readJSONFile(s.path, &list); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range list {
+		s.items[t.ID] = t
+	}
+	return nil
+}
+
+// list retourne les tournois du plus récent au plus ancien
+func (s *tournamentStore) list() []Tournament {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	list := make([]Tournament, 0, len(s.items))
+	for _, t := range s.items {
+		list = append(list, t)
+	}
+	sortTournaments(list)
+	return list
+}
+
+// get retourne le tournoi portant l'identifiant donné
+func (s *tournamentStore) get(id string) (Tournament, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	t, ok := s.items[id]
+	return t, ok
+}
+
+// put enregistre des tournois validés pour owner. Un tournoi sans identifiant
+// remplace celui de même nom et de même date s'il existe, sinon il est créé.
+// Comme pour les decks, seul le propriétaire peut remplacer un tournoi ; le
+// lot est alors refusé entièrement. Le résultat indique pour chacun s'il a
+// été créé.
+func (s *tournamentStore) put(owner string, list []Tournament) ([]Tournament, []bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous := make(map[string]Tournament, len(s.items))
+	for id, t := range s.items {
+		previous[id] = t
+	}
+	created := make([]bool, len(list))
+	for i, t := range list {
+		if t.ID == "" {
+			t.ID = s.findLocked(t.Name, t.Date)
+		}
+		if t.ID == "" {
+			id, err := newRecordID()
+			if err != nil {
+				s.items = previous
+				return nil, nil, err
+			}
+			t.ID = id
+			created[i] = true
+		} else if old, ok := s.items[t.ID]; !ok {
+			s.items = previous
+			return nil, nil, errTournamentNotFound
+		} else if old.Owner != owner {
+			s.items = previous
+			return nil, nil, errTournamentForbidden
+		}
+		t.Owner = owner
+		s.items[t.ID] = t
+		list[i] = t
+	}
+	if err := s.persistLocked(); err != nil {
+		s.items = previous
+		return nil, nil, err
+	}
+	return list, created, nil
+}
+
+// delete supprime un tournoi. Seul son propriétaire peut le supprimer.
+func (s *tournamentStore) delete(id, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, ok := s.items[id]
+	if !ok {
+		return errTournamentNotFound
+	}
+	if old.Owner != owner {
+		return errTournamentForbidden
+	}
+	delete(s.items, id)
+	if err := s.persistLocked(); err != nil {
+		s.items[id] = old
+		return err
+	}
+	return nil
+}
+
+// findLocked retourne l'identifiant du tournoi de même nom et de même date
+func (s *tournamentStore) findLocked(name, date string) string {
+	for id, t := range s.items {
+		if t.Date == date && strings.EqualFold(t.Name, name) {
+			return id
+		}
+	}
+	return ""
+}
+
+// persistLocked réécrit le fichier ; s.mu doit être verrouillé
+func (s *tournamentStore) persistLocked() error {
+	list := make([]Tournament, 0, len(s.items))
+	for _, t := range s.items {
+		list = append(list, t)
+	}
+	sortTournaments(list)
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	return writeJSONFile(s.path, list)
+}
+
+// sortTournaments trie du plus récent au plus ancien, puis par nom
+func sortTournaments(list []Tournament) {
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Date != list[j].Date {
+			return list[i].Date > list[j].Date
+		}
+		return list[i].Name < list[j].Name
+	})
+}
+
+// normalize valide un tournoi et complète les valeurs par défaut : niveau
+// local, nombre de joueurs au moins égal au classement, archétype repris du
+// deck lié. Chaque joueur n'est classé qu'une fois ; les ex æquo sont admis.
+func (t *Tournament) normalize() error {
+	t.Name = strings.TrimSpace(t.Name)
+	if t.Name == "" {
+		return errors.New("name manquant")
+	}
+	if _, err := time.Parse("2006-01-02", t.Date); err != nil {
+		return fmt.Errorf("%s: date invalide (AAAA-MM-JJ): %q", t.Name, t.Date)
+	}
+	format, ok := normalizeFormat(t.Format)
+	if !ok {
+		return fmt.Errorf("%s: format inconnu: %q", t.Name, t.Format)
+	}
+	t.Format = format
+	switch {
+	case t.Tier == 0:
+		t.Tier = tierLocal
+	case t.Tier < tierPremier || t.Tier > tierLocal:
+		return fmt.Errorf("%s: tier invalide: %d (1, 2 ou 3)", t.Name, t.Tier)
+	}
+	if t.Players < 0 || t.TopCut < 0 {
+		return fmt.Errorf("%s: players et top_cut doivent être positifs", t.Name)
+	}
+	if t.Standings == nil {
+		t.Standings = []Standing{}
+	}
+	t.Players = max(t.Players, len(t.Standings))
+
+	for i := range t.Standings {
+		st := &t.Standings[i]
+		st.Player = strings.TrimSpace(st.Player)
+		if st.Placement <= 0 || st.Player == "" {
+			return fmt.Errorf("%s: classement n°%d: placement et player requis", t.Name, i+1)
+		}
+		if st.DeckID != "" {
+			d, ok := decks.get(st.DeckID)
+			if !ok {
+				return fmt.Errorf("%s: deck inconnu pour %s: %s", t.Name, st.Player, st.DeckID)
+			}
+			if st.Archetype == "" {
+				st.Archetype = d.DeckArchtype
+			}
+		}
+	}
+	sort.SliceStable(t.Standings, func(i, j int) bool { return t.Standings[i].Placement < t.Standings[j].Placement })
+
+	// Une place partagée (ex æquo) saute les suivantes : 1, 2, 3, 3, 5
+	players := make(map[string]bool, len(t.Standings))
+	for i, st := range t.Standings {
+		if players[strings.ToLower(st.Player)] {
+			return fmt.Errorf("%s: %s classé plusieurs fois", t.Name, st.Player)
+		}
+		players[strings.ToLower(st.Player)] = true
+		if i > 0 && st.Placement != t.Standings[i-1].Placement && st.Placement <= i {
+			return fmt.Errorf("%s: place %d de %s déjà occupée par un ex æquo", t.Name, st.Placement, st.Player)
+		}
+	}
+	return nil
+}
+
+// tournamentTopDecks retourne les decks liés aux classements, du tournoi le
+// plus récent au plus ancien, complétés par les informations du tournoi
+func tournamentTopDecks() []TopDeck {
+	if tournaments == nil {
+		return nil
+	}
+	var list []TopDeck
+	for _, t := range tournaments.list() {
+		for _, st := range t.Standings {
+			if st.DeckID == "" {
+				continue
+			}
+			d, ok := decks.get(st.DeckID)
+			if !ok {
+				continue
+			}
+			d.Tournament, d.Date, d.Player = t.Name, t.Date, st.Player
+			d.Placement = placementLabel(st.Placement)
+			if st.Archetype != "" {
+				d.DeckArchtype = st.Archetype
+			}
+			list = append(list, d)
+		}
+	}
+	return list
+}
+
+// placementLabel formate une place comme les top decks historiques
+func placementLabel(n int) string {
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+	return fmt.Sprintf("%d%s Place", n, suffix)
+}
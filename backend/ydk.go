@@ -0,0 +1,252 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// maxYDKBody borne la taille d'un fichier .ydk envoyé à l'API
+const maxYDKBody = 64 << 10
+
+// ydkDeck contient les codes (passcodes) des trois sections d'un fichier .ydk
+type ydkDeck struct {
+	Main  []int
+	Extra []int
+	Side  []int
+}
+
+// parseYDK lit un fichier .ydk : sections #main, #extra et !side, un code par
+// ligne. Les autres lignes commençant par # sont des commentaires.
+func parseYDK(r io.Reader) (ydkDeck, error) {
+	deck := ydkDeck{Main: []int{}, Extra: []int{}, Side: []int{}}
+	section := &deck.Main
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+		case strings.EqualFold(line, "#main"):
+			section = &deck.Main
+		case strings.EqualFold(line, "#extra"):
+			section = &deck.Extra
+		case strings.EqualFold(line, "!side"):
+			section = &deck.Side
+		case strings.HasPrefix(line, "#"):
+		default:
+			id, err := strconv.Atoi(line)
+			if err != nil || id <= 0 {
+				return ydkDeck{}, fmt.Errorf("ligne %d: code de carte invalide: %q", n, line)
+			}
+			*section = append(*section, id)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return ydkDeck{}, err
+	}
+	return deck, nil
+}
+
+// writeYDK écrit le deck au format .ydk. Les cartes dont le code n'a pas été
+// trouvé sont listées en commentaire pour ne pas être perdues silencieusement.
+func writeYDK(w io.Writer, deck ydkDeck, unresolved []string) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "#created by AleaChallenge")
+	for _, name := range unresolved {
+		fmt.Fprintf(bw, "#unresolved %s\n", name)
+	}
+	sections := []struct {
+		header string
+		ids    []int
+	}{{"#main", deck.Main}, {"#extra", deck.Extra}, {"!side", deck.Side}}
+	for _, s := range sections {
+		fmt.Fprintln(bw, s.header)
+		for _, id := range s.ids {
+			fmt.Fprintln(bw, id)
+		}
+	}
+	return bw.Flush()
+}
+
+// ResolvedDeck est un deck dont les codes ont été remplacés par les cartes
+type ResolvedDeck struct {
+	Main    []Card   `json:"main"`
+	Extra   []Card   `json:"extra"`
+	Side    []Card   `json:"side"`
+	Unknown []int    `json:"unknown"`        // codes introuvables
+	Deck    *TopDeck `json:"deck,omitempty"` // deck enregistré avec save=true
+}
+
+// resolveYDK retrouve la carte de chaque code. Les codes inconnus sont
+// renvoyés à part.
+func resolveYDK(ctx context.Context, deck ydkDeck) (ResolvedDeck, error) {
+	resolved := ResolvedDeck{Unknown: []int{}}
+	resolve := func(ids []int) ([]Card, error) {
+		found := make([]Card, 0, len(ids))
+		for _, id := range ids {
+			card, err := cards.CardByID(ctx, id)
+			if errors.Is(err, errCardNotFound) {
+				resolved.Unknown = append(resolved.Unknown, id)
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			found = append(found, card)
+		}
+		return found, nil
+	}
+
+	var err error
+	if resolved.Main, err = resolve(deck.Main); err != nil {
+		return ResolvedDeck{}, err
+	}
+	if resolved.Extra, err = resolve(deck.Extra); err != nil {
+		return ResolvedDeck{}, err
+	}
+	if resolved.Side, err = resolve(deck.Side); err != nil {
+		return ResolvedDeck{}, err
+	}
+	return resolved, nil
+}
+
+// ydkOf retrouve le code de chaque carte d'un deck à partir de son nom
+func ydkOf(ctx context.Context, d TopDeck) (ydkDeck, []string, error) {
+	var unresolved []string
+	codes := func(names []string) ([]int, error) {
+		ids := make([]int, 0, len(names))
+		for _, name := range names {
+			card, err := cards.CardByName(ctx, name)
+			if errors.Is(err, errCardNotFound) {
+				unresolved = append(unresolved, name)
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			ids = append(ids, card.ID)
+		}
+		return ids, nil
+	}
+
+	var deck ydkDeck
+	var err error
+	if deck.Main, err = codes(d.MainCards); err != nil {
+		return ydkDeck{}, nil, err
+	}
+	if deck.Extra, err = codes(d.ExtraCards); err != nil {
+		return ydkDeck{}, nil, err
+	}
+	if deck.Side, err = codes(d.SideCards); err != nil {
+		return ydkDeck{}, nil, err
+	}
+	return deck, unresolved, nil
+}
+
+func cardNamesOf(list []Card) []string {
+	names := make([]string, len(list))
+	for i, c := range list {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// findDeck cherche un deck enregistré puis parmi les top decks
+func findDeck(id string) (TopDeck, bool) {
+	if d, ok := decks.get(id); ok {
+		return d, true
+	}
+	for _, d := range getAllTopDecks() {
+		if d.ID == id {
+			return d, true
+		}
+	}
+	return TopDeck{}, false
+}
+
+// importYDK lit un fichier .ydk envoyé en POST et renvoie les cartes de
+// chaque section. Avec save=true, le deck est aussi enregistré au nom de
+// l'utilisateur X-User (nom du deck dans le paramètre name).
+func importYDK(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSON(w, http.StatusMethodNotAllowed, APIResponse{Error: "Méthode non autorisée", Status: "error"})
+		return
+	}
+	save := r.URL.Query().Get("save") == "true"
+	user := currentUser(r)
+	if save && user == "" {
+		writeJSON(w, http.StatusUnauthorized, APIResponse{Error: "En-tête 'X-User' requis", Status: "error"})
+		return
+	}
+
+	deck, err := parseYDK(http.MaxBytesReader(w, r.Body, maxYDKBody))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, APIResponse{Error: "Fichier .ydk invalide: " + err.Error(), Status: "error"})
+		return
+	}
+	resolved, err := resolveYDK(r.Context(), deck)
+	if err != nil {
+		writeJSON(w, http.StatusBadGateway, APIResponse{Error: err.Error(), Status: "error"})
+		return
+	}
+
+	if save {
+		name := strings.TrimSpace(r.URL.Query().Get("name"))
+		if name == "" {
+			name = "Deck importé"
+		}
+		d := TopDeck{
+			DeckName:   name,
+			MainCards:  cardNamesOf(resolved.Main),
+			ExtraCards: cardNamesOf(resolved.Extra),
+			SideCards:  cardNamesOf(resolved.Side),
+		}
+		tagArchetype(r.Context(), &d)
+		created, err := decks.create(user, d)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, APIResponse{Error: "Enregistrement du deck impossible: " + err.Error(), Status: "error"})
+			return
+		}
+		resolved.Deck = &created
+	}
+
+	writeJSON(w, http.StatusOK, APIResponse{Data: resolved, Status: "success"})
+}
+
+// exportYDK télécharge un deck enregistré ou un top deck (id) au format .ydk
+func exportYDK(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		w.Header().Set("Content-Type", "application/json")
+		writeJSON(w, http.StatusBadRequest, APIResponse{Error: "Paramètre 'id' requis", Status: "error"})
+		return
+	}
+	d, ok := findDeck(id)
+	if !ok {
+		w.Header().Set("Content-Type", "application/json")
+		writeJSON(w, http.StatusNotFound, APIResponse{Error: "Deck non trouvé", Status: "error"})
+		return
+	}
+	deck, unresolved, err := ydkOf(r.Context(), d)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		writeJSON(w, http.StatusBadGateway, APIResponse{Error: err.Error(), Status: "error"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", id+".ydk"))
+	w.Header().Set("X-Unresolved-Cards", strconv.Itoa(len(unresolved)))
+	writeYDK(w, deck, unresolved)
+}
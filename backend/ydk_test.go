@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseYDK(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    ydkDeck
+		wantErr bool
+	}{
+		{
+			name:  "trois sections",
+			input: "#created by ygopro\n#main\n14558127\n14558127\n#extra\n86066372\n!side\n23434538\n",
+			want:  ydkDeck{Main: []int{14558127, 14558127}, Extra: []int{86066372}, Side: []int{23434538}},
+		},
+		{
+			name:  "fins de ligne Windows et lignes vides",
+			input: "#main\r\n\r\n14558127\r\n  23434538  \r\n#extra\r\n!side\r\n",
+			want:  ydkDeck{Main: []int{14558127, 23434538}, Extra: []int{}, Side: []int{}},
+		},
+		{
+			name:  "cartes avant tout en-tête dans le Main Deck",
+			input: "14558127\n#unresolved Kashtira Argodem\n",
+			want:  ydkDeck{Main: []int{14558127}, Extra: []int{}, Side: []int{}},
+		},
+		{name: "code invalide", input: "#main\nAsh Blossom\n", wantErr: true},
+		{name: "code négatif", input: "#main\n-1\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseYDK(strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseYDK() erreur = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseYDK() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteYDKRoundTrip(t *testing.T) {
+	deck := ydkDeck{Main: []int{14558127, 14558127, 23434538}, Extra: []int{86066372}, Side: []int{}}
+	var buf bytes.Buffer
+	if err := writeYDK(&buf, deck, []string{"Kashtira Argodem"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "#unresolved Kashtira Argodem\n") {
+		t.Errorf("carte non résolue absente du fichier:\n%s", buf.String())
+	}
+	got, err := parseYDK(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, deck) {
+		t.Errorf("relu %+v, want %+v", got, deck)
+	}
+}
+
+func TestResolveYDK(t *testing.T) {
+	useFakeCards(t, &fakeCardClient{cards: []Card{
+		{ID: 14558127, Name: "Ash Blossom & Joyous Spring"},
+		{ID: 86066372, Name: "Accesscode Talker"},
+	}})
+	got, err := resolveYDK(context.Background(), ydkDeck{Main: []int{14558127, 1}, Extra: []int{86066372}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Main) != 1 || got.Main[0].Name != "Ash Blossom & Joyous Spring" {
+		t.Errorf("Main = %+v", got.Main)
+	}
+	if len(got.Extra) != 1 || got.Extra[0].ID != 86066372 {
+		t.Errorf("Extra = %+v", got.Extra)
+	}
+	if !reflect.DeepEqual(got.Unknown, []int{1}) {
+		t.Errorf("Unknown = %v, want [1]", got.Unknown)
+	}
+}
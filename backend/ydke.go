@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ydkePrefix est le schéma des URL de deck utilisées par EDOPro
+const ydkePrefix = "ydke://"
+
+// YDKELink est un deck encodé en URL ydke://
+type YDKELink struct {
+	URL        string   `json:"url"`
+	Unresolved []string `json:"unresolved"` // cartes absentes de l'URL faute de code
+}
+
+// encodeYDKE encode le deck en ydke://main!extra!side! où chaque section est
+// la suite des codes en uint32 petit-boutiste, encodée en base64
+func encodeYDKE(deck ydkDeck) string {
+	var b strings.Builder
+	b.WriteString(ydkePrefix)
+	for _, ids := range [][]int{deck.Main, deck.Extra, deck.Side} {
+		raw := make([]byte, 4*len(ids))
+		for i, id := range ids {
+			binary.LittleEndian.PutUint32(raw[4*i:], uint32(id))
+		}
+		b.WriteString(base64.StdEncoding.EncodeToString(raw))
+		b.WriteByte('!')
+	}
+	return b.String()
+}
+
+// parseYDKE décode une URL ydke:// en codes de cartes
+func parseYDKE(link string) (ydkDeck, error) {
+	link = strings.TrimSpace(link)
+	if !strings.HasPrefix(link, ydkePrefix) {
+		return ydkDeck{}, errors.New("l'URL doit commencer par ydke://")
+	}
+	parts := strings.Split(strings.TrimPrefix(link, ydkePrefix), "!")
+	if len(parts) < 3 {
+		return ydkDeck{}, errors.New("sections main, extra et side attendues")
+	}
+	var sections [3][]int
+	for s := range sections {
+		raw, err := base64.StdEncoding.DecodeString(parts[s])
+		if err != nil {
+			return ydkDeck{}, fmt.Errorf("section %d: base64 invalide", s+1)
+		}
+		if len(raw)%4 != 0 {
+			return ydkDeck{}, fmt.Errorf("section %d: longueur invalide", s+1)
+		}
+		sections[s] = make([]int, len(raw)/4)
+		for i := range sections[s] {
+			sections[s][i] = int(binary.LittleEndian.Uint32(raw[4*i:]))
+		}
+	}
+	return ydkDeck{Main: sections[0], Extra: sections[1], Side: sections[2]}, nil
+}
+
+// encodeDeckYDKE encode en URL ydke:// un deck enregistré ou un top deck
+// (id), ou un deck envoyé en POST
+func encodeDeckYDKE(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	d, status, err := deckFromRequest(w, r)
+	if err != nil {
+		writeJSON(w, status, APIResponse{Error: err.Error(), Status: "error"})
+		return
+	}
+	deck, unresolved, err := ydkOf(r.Context(), d)
+	if err != nil {
+		writeJSON(w, http.StatusBadGateway, APIResponse{Error: err.Error(), Status: "error"})
+		return
+	}
+	if unresolved == nil {
+		unresolved = []string{}
+	}
+
+	writeJSON(w, http.StatusOK, APIResponse{Data: YDKELink{URL: encodeYDKE(deck), Unresolved: unresolved}, Status: "success"})
+}
+
+// parseDeckYDKE décode l'URL ydke:// passée dans le paramètre url et renvoie
+// les cartes de chaque section
+func parseDeckYDKE(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	link := r.URL.Query().Get("url")
+	if link == "" {
+		writeJSON(w, http.StatusBadRequest, APIResponse{Error: "Paramètre 'url' requis", Status: "error"})
+		return
+	}
+	// Un + non encodé dans la query string arrive sous forme d'espace
+	deck, err := parseYDKE(strings.ReplaceAll(link, " ", "+"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, APIResponse{Error: "URL ydke invalide: " + err.Error(), Status: "error"})
+		return
+	}
+	resolved, err := resolveYDK(r.Context(), deck)
+	if err != nil {
+		writeJSON(w, http.StatusBadGateway, APIResponse{Error: err.Error(), Status: "error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, APIResponse{Data: resolved, Status: "success"})
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEncodeYDKE(t *testing.T) {
+	tests := []struct {
+		name string
+		deck ydkDeck
+		want string
+	}{
+		{"deck vide", ydkDeck{}, "ydke://!!!"},
+		// 14558127 = 0x00DE23AF, en petit-boutiste AF 23 DE 00
+		{"une carte", ydkDeck{Main: []int{14558127}}, "ydke://ryPeAA==!!!"},
+		{"trois sections", ydkDeck{Main: []int{1}, Extra: []int{2}, Side: []int{3}}, "ydke://AQAAAA==!AgAAAA==!AwAAAA==!"},
+	}
+	for _, tt := range tests {
+		if got := encodeYDKE(tt.deck); got != tt.want {
+			t.Errorf("%s: encodeYDKE() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestYDKERoundTrip(t *testing.T) {
+	decks := []ydkDeck{
+		{Main: []int{}, Extra: []int{}, Side: []int{}},
+		{Main: []int{14558127, 14558127, 14558127, 23434538}, Extra: []int{86066372}, Side: []int{}},
+		{Main: []int{572850, 84330567}, Extra: []int{}, Side: []int{32909498, 25311006, 4294967295}},
+	}
+	for _, deck := range decks {
+		link := encodeYDKE(deck)
+		got, err := parseYDKE(link)
+		if err != nil {
+			t.Fatalf("parseYDKE(%q): %v", link, err)
+		}
+		if !reflect.DeepEqual(got, deck) {
+			t.Errorf("parseYDKE(%q) = %+v, want %+v", link, got, deck)
+		}
+	}
+}
+
+func TestParseYDKEErrors(t *testing.T) {
+	tests := []struct{ name, link string }{
+		{"préfixe manquant", "https://example.com/!!!"},
+		{"sections manquantes", "ydke://ryPeAA==!"},
+		{"base64 invalide", "ydke://@@@@!!!"},
+		{"longueur invalide", "ydke://ryPe!!!"},
+	}
+	for _, tt := range tests {
+		if _, err := parseYDKE(tt.link); err == nil {
+			t.Errorf("%s: parseYDKE(%q) sans erreur", tt.name, tt.link)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// errCardNotFound est renvoyée quand aucune carte ne correspond à l'identifiant
+// ou au nom demandé
+var errCardNotFound = errors.New("carte non trouvée")
+
+// CardClient donne accès aux données de cartes. Les handlers ne dépendent que
+// de cette interface, ce qui permet de les tester avec un faux client.
+type CardClient interface {
+	SearchCards(ctx context.Context, q CardQuery) ([]Card, error)
+	CardByID(ctx context.Context, id int) (Card, error)
+	CardByName(ctx context.Context, name string) (Card, error)
+	Archetypes(ctx context.Context) ([]string, error)
+}
+
+// requestTimeout borne les appels faits pour le compte d'une requête HTTP
+const requestTimeout = 30 * time.Second
+
+// ygoClient interroge l'API YGOProDeck en passant par un cache de réponses
+type ygoClient struct {
+	baseURL string
+	http    *http.Client
+	cache   *responseCache
+}
+
+func newYGOClient(baseURL string, cache *responseCache) *ygoClient {
+	return &ygoClient{
+		baseURL: baseURL,
+		http:    &http.Client{},
+		cache:   cache,
+	}
+}
+
+func (c *ygoClient) SearchCards(ctx context.Context, q CardQuery) ([]Card, error) {
+	var result struct {
+		Data []Card `json:"data"`
+	}
+	if err := c.get(ctx, "cardinfo.php", q.params(), &result); err != nil {
+		return nil, err
+	}
+
+	// Certains critères ne sont pas gérés par YGOProDeck
+	cards := []Card{}
+	for _, card := range result.Data {
+		if q.match(card) {
+			cards = append(cards, card)
+		}
+	}
+	return cards, nil
+}
+
+func (c *ygoClient) CardByID(ctx context.Context, id int) (Card, error) {
+	var result struct {
+		Data []Card `json:"data"`
+	}
+	params := url.Values{"id": {strconv.Itoa(id)}, "misc": {"yes"}}
+	if err := c.get(ctx, "cardinfo.php", params, &result); err != nil {
+		return Card{}, err
+	}
+	if len(result.Data) == 0 {
+		return Card{}, errCardNotFound
+	}
+	return result.Data[0], nil
+}
+
+func (c *ygoClient) CardByName(ctx context.Context, name string) (Card, error) {
+	var result struct {
+		Data []Card `json:"data"`
+	}
+	params := url.Values{"name": {name}, "misc": {"yes"}}
+	if err := c.get(ctx, "cardinfo.php", params, &result); err != nil {
+		return Card{}, err
+	}
+	if len(result.Data) == 0 {
+		return Card{}, errCardNotFound
+	}
+	return result.Data[0], nil
+}
+
+func (c *ygoClient) Archetypes(ctx context.Context) ([]string, error) {
+	var raw []struct {
+		Name string `json:"archetype_name"`
+	}
+	if err := c.get(ctx, "archetypes.php", nil, &raw); err != nil {
+		return nil, err
+	}
+	archetypes := make([]string, len(raw))
+	for i, a := range raw {
+		archetypes[i] = a.Name
+	}
+	return archetypes, nil
+}
+
+// dbVersion décrit la version de la base renvoyée par checkDBVer.php
+type dbVersion struct {
+	Version    string `json:"database_version"`
+	LastUpdate string `json:"last_update"`
+}
+
+// DBVersion interroge checkDBVer.php sans passer par le cache
+func (c *ygoClient) DBVersion(ctx context.Context) (dbVersion, error) {
+	var versions []dbVersion
+	if err := c.getFresh(ctx, "checkDBVer.php", nil, &versions); err != nil {
+		return dbVersion{}, err
+	}
+	if len(versions) == 0 {
+		return dbVersion{}, errors.New("checkDBVer.php: réponse vide")
+	}
+	return versions[0], nil
+}
+
+// AllCards télécharge toute la base de cartes sans passer par le cache
+func (c *ygoClient) AllCards(ctx context.Context) ([]Card, error) {
+	var result struct {
+		Data []Card `json:"data"`
+	}
+	params := url.Values{"misc": {"yes"}}
+	if err := c.getFresh(ctx, "cardinfo.php", params, &result); err != nil {
+		return nil, err
+	}
+	return result.Data, nil
+}
+
+// BanlistCards télécharge les cartes présentes sur la banlist YGOProDeck du
+// format donné (tcg, ocg ou goat) sans passer par le cache
+func (c *ygoClient) BanlistCards(ctx context.Context, format string) ([]Card, error) {
+	var result struct {
+		Data []Card `json:"data"`
+	}
+	params := url.Values{"banlist": {format}}
+	if err := c.getFresh(ctx, "cardinfo.php", params, &result); err != nil {
+		return nil, err
+	}
+	return result.Data, nil
+}
+
+// get appelle un endpoint YGOProDeck et décode la réponse dans v. Les réponses
+// sont servies depuis le cache tant qu'elles n'ont pas expiré.
+func (c *ygoClient) get(ctx context.Context, endpoint string, params url.Values, v interface{}) error {
+	key := cacheKey(endpoint, params)
+	body, ok := c.cache.get(key)
+	if !ok {
+		ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+		defer cancel()
+
+		var err error
+		body, err = c.fetch(ctx, endpoint, params)
+		if err != nil {
+			return err
+		}
+		c.cache.set(key, body)
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("réponse YGOProDeck invalide (%s): %w", endpoint, err)
+	}
+	return nil
+}
+
+// getFresh appelle un endpoint YGOProDeck sans lire ni alimenter le cache. Le
+// délai maximal est laissé à l'appelant, le téléchargement complet étant long.
+func (c *ygoClient) getFresh(ctx context.Context, endpoint string, params url.Values, v interface{}) error {
+	body, err := c.fetch(ctx, endpoint, params)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("réponse YGOProDeck invalide (%s): %w", endpoint, err)
+	}
+	return nil
+}
+
+func (c *ygoClient) fetch(ctx context.Context, endpoint string, params url.Values) ([]byte, error) {
+	apiURL := fmt.Sprintf("%s/%s", c.baseURL, endpoint)
+	if len(params) > 0 {
+		apiURL += "?" + params.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return body, nil
+	case resp.StatusCode == http.StatusBadRequest && strings.HasPrefix(endpoint, "cardinfo"):
+		// YGOProDeck répond 400 quand aucune carte ne correspond à la recherche
+		return []byte(`{"data":[]}`), nil
+	default:
+		return nil, fmt.Errorf("YGOProDeck %s: HTTP %d", endpoint, resp.StatusCode)
+	}
+}
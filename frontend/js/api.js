@@ -41,7 +41,7 @@ async function getArchetypesAPI() {
 
 // Récupérer la banlist
 async function getBanlistAPI(format = 'TCG') {
-    return fetchAPI('/banlist', { format });
+    return fetchAPI('/banlist', { format, lang: 'fr' });
 }
 
 // Récupérer les top decks
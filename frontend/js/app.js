@@ -123,7 +123,7 @@ function showCardDetails(card) {
         <div class="modal-card-detail">
             <strong>Type:</strong> ${card.type || 'Unknown'}
         </div>
-        ${card.archtype ? `<div class="modal-card-detail"><strong>Archétype:</strong> ${card.archtype}</div>` : ''}
+        ${card.archetype ? `<div class="modal-card-detail"><strong>Archétype:</strong> ${card.archetype}</div>` : ''}
         ${statsHTML}
         ${setsHTML}
         <div class="modal-card-detail" style="margin-top: 20px;">
@@ -333,7 +333,7 @@ function displayBanlist(banlists) {
                 cardDiv.style.borderRadius = '5px';
                 
                 let statusColor = '#ff6b6b'; // Banned - red
-                if (card.ban_status.includes('Limited')) {
+                if (card.ban_status !== 'forbidden') {
                     statusColor = '#ffed4e'; // Limited - yellow
                 }
                 
@@ -344,7 +344,7 @@ function displayBanlist(banlists) {
                 cardDiv.innerHTML = `
                     <div style="color: #ffd700; font-weight: bold; margin-bottom: 8px;">${card.card_name}</div>
                     <div style="color: ${statusColor}; font-weight: bold; display: inline-block; background: ${statusColor}20; padding: 4px 8px; border-radius: 3px; margin-bottom: 8px;">
-                        ${card.ban_status}
+                        ${card.ban_label || card.ban_status}
                     </div>
                     ${dateInfo}
                 `;